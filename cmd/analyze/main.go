@@ -0,0 +1,195 @@
+package main
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"acquire/internal/util"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+// analyze
+// searches a position and prints the engine's top moves, e.g.
+//
+//	go run ./cmd/analyze --rounds 5000 --top 3 merger.txt
+//
+// the position is in the format read by acquire.ParsePosition
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+type options struct {
+	rounds   int
+	duration time.Duration
+	top      int
+	seed     int64
+
+	// where to write the search tree, and how deep
+	dotPath  string
+	jsonPath string
+	depth    int
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: analyze [flags] position.txt")
+		flags.PrintDefaults()
+	}
+
+	opts := options{}
+	flags.IntVar(&opts.rounds, "rounds", 1000, "number of games to play out")
+	flags.DurationVar(&opts.duration, "time", 0, "search for this long instead of a number of rounds, e.g. 10s")
+	flags.IntVar(&opts.top, "top", 5, "number of actions to show")
+	flags.Int64Var(&opts.seed, "seed", 1, "seed for dealing hands the position leaves out, and for the search")
+	flags.StringVar(&opts.dotPath, "dot", "", "path to write the search tree to as graphviz")
+	flags.StringVar(&opts.jsonPath, "json", "", "path to write the search tree to as json")
+	flags.IntVar(&opts.depth, "depth", 2, "how many levels of the search tree to write")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected a position file")
+	}
+
+	if opts.rounds < 1 || opts.top < 1 {
+		return errors.New("--rounds and --top must be at least 1")
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	rand.Seed(opts.seed)
+	game, err := acquire.ParsePosition(string(data))
+	if err != nil {
+		return fmt.Errorf("position %s: %w", flags.Arg(0), err)
+	}
+
+	if game.IsTerminal() {
+		return errors.New("the game is already over")
+	}
+
+	start := time.Now()
+	tree := search(game, opts)
+	printAnalysis(out, tree, opts.top, time.Since(start))
+
+	if opts.dotPath != "" {
+		err = writeTree(opts.dotPath, func(w io.Writer) error { return tree.WriteDOT(w, opts.depth) })
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.jsonPath != "" {
+		err = writeTree(opts.jsonPath, func(w io.Writer) error { return tree.WriteJSON(w, opts.depth) })
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeTree(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return write(file)
+}
+
+// search
+// searches the game for the budget in the options
+func search(game *acquire.Game, opts options) *ai.SearchTree {
+	tree := ai.NewSearchTree(game, opts.seed)
+
+	// there's no best action until something has been searched, however short the time
+	tree.SearchRounds(1)
+
+	if opts.duration > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), opts.duration)
+		defer cancel()
+		tree.Search(ctx)
+	} else {
+		tree.SearchRounds(opts.rounds - 1)
+	}
+
+	return tree
+}
+
+// printAnalysis
+// a table of the top actions, with how often each was searched, the average reward for the player the
+// search plays for, and each player's estimated chance of winning after it. the action the engine would
+// play (the best reward) is marked with a *
+func printAnalysis(out io.Writer, tree *ai.SearchTree, top int, elapsed time.Duration) {
+	root := tree.Root()
+	game := root.Game()
+	mover := game.ActivePlayer()
+	searcher := game.GetPlayerById(int(tree.Player()))
+	players := game.PlayerSlice()
+
+	fmt.Fprintf(out, "%s to play (%s), searched %d games in %s\n",
+		mover.Name(), game.NextActionType.String(), tree.Rounds(), elapsed.Round(time.Millisecond))
+
+	// the search goes by the rewards of the player whose turn it is, even when someone else is deciding
+	if searcher.Id != mover.Id {
+		fmt.Fprintf(out, "rewards are for %s, whose turn it is\n", searcher.Name())
+	}
+	fmt.Fprintln(out)
+
+	ranked := root.RankedChildren()
+	if len(ranked) > top {
+		ranked = ranked[:top]
+	}
+
+	descriptions := make([]string, len(ranked))
+	width := len("Action")
+	for i, child := range ranked {
+		descriptions[i] = child.Action().(acquire.IAction).String(game)
+		width = util.Max(width, len(descriptions[i]))
+	}
+
+	header := fmt.Sprintf("   #  %-*s  %7s  %6s", width, "Action", "Visits", "Reward")
+	for _, p := range players {
+		header += fmt.Sprintf("  %9s", p.Name())
+	}
+	fmt.Fprintln(out, header)
+	fmt.Fprintln(out, strings.Repeat("-", len(header)))
+
+	best := tree.BestAction()
+	for i, child := range ranked {
+		marker := " "
+		if child.Action() == best {
+			marker = "*"
+		}
+
+		line := fmt.Sprintf("%s %2d  %-*s  %7d  %6.3f", marker, i+1, width, descriptions[i], child.Visits(), child.WinRate(tree.Player()))
+		for _, p := range players {
+			line += fmt.Sprintf("  %8.1f%%", 100*child.WinRate(gmcts.Player(p.Id)))
+		}
+		fmt.Fprintln(out, line)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "position.txt")
+	err := os.WriteFile(path, []byte(`
+		players: 2
+		phase: merge
+		last: 3A
+		player 1: stocks W4
+		a  W  W  ■  T  T  T  □  □  □  □  □  □
+	`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	err = run([]string{"--rounds", "100", "--top", "2", path}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if !strings.HasPrefix(lines[0], "Player 1 to play (Merge), searched 100 games") {
+		t.Fatalf("unexpected summary line '%s'", lines[0])
+	}
+
+	// summary, blank, header, rule, then the top 2 actions
+	if len(lines) != 6 || !strings.Contains(out.String(), "chooses to merge Worldwide into Tower") {
+		t.Fatalf("expected the top 2 merge actions, got:\n%s", out.String())
+	}
+
+	if !strings.Contains(out.String(), "*") {
+		t.Fatal("the best action should be marked")
+	}
+}
+
+// analyzePosition
+// the lines printed for the position with the arguments
+func analyzePosition(t *testing.T, position string, args ...string) []string {
+	path := filepath.Join(t.TempDir(), "position.txt")
+	err := os.WriteFile(path, []byte(position), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	err = run(append(args, path), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+func TestAnalyzeNoTime(t *testing.T) {
+	// even without time for a single round there's a move to suggest
+	lines := analyzePosition(t, `player 1: tiles 1A 5C`, "--time", "1ns")
+	if !strings.Contains(lines[0], "searched 1 games") || !strings.HasPrefix(lines[len(lines)-1], "*") {
+		t.Fatalf("one round should have been searched, with its action marked, got:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestAnalyzeRewardPlayer(t *testing.T) {
+	// player 2 decides what to do with their Worldwide shares during player 1's turn
+	lines := analyzePosition(t, `
+		players: 2
+		phase: merge
+		last: 3A
+		merging: W1
+		merging player: 2
+		player 2: stocks W4
+		a  W  W  ■  T  T  T  □  □  □  □  □  □
+	`, "--rounds", "200")
+
+	if !strings.HasPrefix(lines[0], "Player 2 to play") || lines[1] != "rewards are for Player 1, whose turn it is" {
+		t.Fatalf("unexpected summary:\n%s", strings.Join(lines, "\n"))
+	}
+
+	// the reward column is player 1's chance of winning, and the best reward is marked
+	best, marked := -1.0, 0.0
+	for _, line := range lines[5:] {
+		fields := strings.Fields(strings.TrimPrefix(line, "*"))
+		reward, _ := strconv.ParseFloat(fields[len(fields)-3], 64)
+		p1, _ := strconv.ParseFloat(strings.TrimSuffix(fields[len(fields)-2], "%"), 64)
+
+		if math.Abs(100*reward-p1) > 0.1 {
+			t.Errorf("the reward should be player 1's, got %.3f and %.1f%% in %q", reward, p1, line)
+		}
+
+		best = math.Max(best, reward)
+		if strings.HasPrefix(line, "*") {
+			marked = reward
+		}
+	}
+
+	if marked != best {
+		t.Errorf("the action with the best reward (%.3f) should be marked, %.3f was", best, marked)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"acquire/internal/engine"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+)
+
+// engine
+// plays our MCTS agent over the engine protocol on stdin and stdout (see the engine package), e.g.
+//
+//	go run ./cmd/engine --rounds 2000
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+func run(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("engine", flag.ContinueOnError)
+
+	name := flags.String("name", "acquire-mcts", "the name the engine gives")
+	rounds := flags.Int("rounds", 1000, "number of games to play out for each move, when go isn't given a limit")
+	seed := flags.Int64("seed", 0, "seed for dealing the bank in each position (0 = random)")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *rounds < 1 {
+		return fmt.Errorf("--rounds must be at least 1")
+	}
+
+	if *seed != 0 {
+		rand.Seed(*seed)
+	}
+
+	return engine.NewEngine(*name, *rounds).Run(in, out)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEngine(t *testing.T) {
+	in := strings.NewReader(strings.Join([]string{
+		"acquire",
+		"position players: 2 / phase: merge / last: 3A / player 1: stocks W4 / a  W  W  ■  T  T  T  □  □  □  □  □  □",
+		"go rounds 50",
+		"quit",
+	}, "\n"))
+
+	out := bytes.Buffer{}
+	err := run([]string{"--name", "tester", "--seed", "1"}, in, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || lines[0] != "id name tester" || lines[1] != "acquireok" {
+		t.Fatalf("unexpected replies:\n%s", out.String())
+	}
+
+	// player 1 is merging their 4 worldwide shares
+	move, _ := strings.CutPrefix(lines[2], "bestmove ")
+	if move != "hold" && !strings.HasPrefix(move, "sell:") && !strings.HasPrefix(move, "trade:") {
+		t.Errorf("expected a merge move, got %s", lines[2])
+	}
+}
+
+func TestEngineFlags(t *testing.T) {
+	err := run([]string{"--rounds", "0"}, strings.NewReader(""), &bytes.Buffer{})
+	if err == nil {
+		t.Error("expected --rounds 0 to be rejected")
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	OutputText  = "text"
+	OutputQuiet = "quiet"
+)
+
+// configFile
+// the json layout of a game setup file, for example:
+//
+//	{
+//		"seed": 42,
+//		"output": "quiet",
+//		"render": "ansi",
+//		"palette": "colorblind",
+//		"tui": true,
+//		"save": "lunch.json",
+//		"from": "endgame.txt",
+//		"rules": {"starting_money": 8000},
+//		"engine_time": "500ms",
+//		"move_time": "5s",
+//		"fallback": "heuristic",
+//		"seats": [
+//			{"name": "Alice", "agent": "human"},
+//			{"name": "Bot", "agent": "mcts:500"},
+//			{"name": "Visitor", "agent": "engine:./bots/greedy --quiet"}
+//		]
+//	}
+type configFile struct {
+	Seed    int64         `json:"seed"`
+	Output  string        `json:"output"`
+	Render  string        `json:"render"`
+	Palette string        `json:"palette"`
+	TUI     bool          `json:"tui"`
+	Save    string        `json:"save"`
+	From    string        `json:"from"`
+	Rules   acquire.Rules `json:"rules"`
+	Seats   []configSeat  `json:"seats"`
+
+	EngineTime string `json:"engine_time"`
+	MoveTime   string `json:"move_time"`
+	Fallback   string `json:"fallback"`
+}
+
+type configSeat struct {
+	Name  string `json:"name"`
+	Agent string `json:"agent"`
+}
+
+// readConfigFile
+// loads and validates a game setup file into a game config
+func readConfigFile(path string) (*GameConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := parseConfigFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+func parseConfigFile(data []byte) (*GameConfig, error) {
+	// rules left out of the file keep their defaults
+	file := configFile{Rules: acquire.DefaultRules}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&file)
+	if err != nil {
+		return nil, describeJSONError(data, err)
+	}
+
+	if len(file.Seats) < 2 || len(file.Seats) > acquire.MAX_PLAYERS {
+		return nil, fmt.Errorf("there must be 2-%d seats, found %d", acquire.MAX_PLAYERS, len(file.Seats))
+	}
+
+	config := newGameConfig(len(file.Seats))
+	config.Seed = file.Seed
+	config.TUI = file.TUI
+	config.Save = file.Save
+	config.From = file.From
+	config.PlayerNames = make([]string, len(file.Seats))
+
+	switch file.Output {
+	case "", OutputText:
+	case OutputQuiet:
+		config.Quiet = true
+	default:
+		return nil, fmt.Errorf("output must be '%s' or '%s', was '%s'", OutputText, OutputQuiet, file.Output)
+	}
+
+	if file.Render != "" {
+		config.Render, err = checkRender(file.Render)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if file.Palette != "" {
+		config.Palette, err = acquire.ParsePalette(file.Palette)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config.Rules = file.Rules
+	err = config.Rules.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("rules: %w", err)
+	}
+
+	if file.EngineTime != "" {
+		config.EngineTime, err = time.ParseDuration(file.EngineTime)
+		if err != nil {
+			return nil, fmt.Errorf("engine time: %w", err)
+		}
+	}
+
+	if file.MoveTime != "" {
+		config.MoveTime, err = time.ParseDuration(file.MoveTime)
+		if err != nil {
+			return nil, fmt.Errorf("move time: %w", err)
+		}
+	}
+
+	if file.Fallback != "" {
+		config.Fallback, err = ai.ParseFallback(file.Fallback)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names := make(map[string]int)
+	for i, seat := range file.Seats {
+		if seat.Agent == "" {
+			return nil, fmt.Errorf("seat %d is missing an agent (human, random, mcts:strength or engine:command)", i+1)
+		}
+
+		err := config.setAgent(i, seat.Agent)
+		if err != nil {
+			return nil, fmt.Errorf("seat %d: %w", i+1, err)
+		}
+
+		if seat.Name != "" {
+			if other, ok := names[seat.Name]; ok {
+				return nil, fmt.Errorf("seat %d has the same name as seat %d, '%s'", i+1, other, seat.Name)
+			}
+			names[seat.Name] = i + 1
+		}
+
+		config.PlayerNames[i] = seat.Name
+	}
+
+	return config, nil
+}
+
+// describeJSONError
+// json errors only give a byte offset, so turn that into a line number people can find
+func describeJSONError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	var offset int64
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+
+	line := 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+		}
+	}
+
+	return fmt.Errorf("line %d: %w", line, err)
+}
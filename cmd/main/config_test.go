@@ -0,0 +1,201 @@
+package main
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	config, err := parseConfigFile([]byte(`{
+		"seed": 42,
+		"output": "quiet",
+		"rules": {"starting_money": 8000},
+		"seats": [
+			{"name": "Alice", "agent": "human"},
+			{"name": "Bot", "agent": "mcts:300"},
+			{"agent": "random"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.NumPlayers != 3 || config.Seed != 42 || !config.Quiet {
+		t.Fatal("config not read from file")
+	}
+
+	if config.PlayerTypes[1] != AI || config.AIPlayerStrengths[1] != 300 {
+		t.Fatal("seat 2 should be an AI of strength 300")
+	}
+
+	if config.playerName(0) != "Alice" || config.playerName(2) != "" {
+		t.Fatal("seat names not read from file")
+	}
+
+	if config.agentDescription(1) != "MCTS-300" {
+		t.Fatal("seat 2 should be described as MCTS-300")
+	}
+
+	// rules which aren't in the file keep their defaults
+	if config.Rules.StartingMoney != 8000 || config.Rules.SafeChainSize != 12 {
+		t.Fatalf("rules not merged with defaults, got %+v", config.Rules)
+	}
+}
+
+func TestParseConfigFileErrors(t *testing.T) {
+	testErr := func(data string, contains string) {
+		_, err := parseConfigFile([]byte(data))
+		if err == nil {
+			t.Fatalf("expected an error for %s", data)
+		}
+
+		if !strings.Contains(err.Error(), contains) {
+			t.Fatalf("error '%s' should mention '%s'", err, contains)
+		}
+	}
+
+	testErr(`{"seats": [{"agent": "human"}]}`, "2-6 seats")
+	testErr(`{"seats": [{"agent": "human"}, {"agent": "robot"}]}`, "seat 2: unknown agent type 'robot'")
+	testErr(`{"seats": [{"agent": "human"}, {"name": "x"}]}`, "seat 2 is missing an agent")
+	testErr(`{"seats": [{"name": "x", "agent": "human"}, {"name": "x", "agent": "random"}]}`, "same name as seat 1")
+	testErr(`{"output": "loud", "seats": [{"agent": "human"}, {"agent": "human"}]}`, "output must be")
+	testErr(`{"rules": {"safe_chain_size": 0}, "seats": [{"agent": "human"}, {"agent": "human"}]}`, "rules: safe chain size")
+	testErr(`{"seats": [{"agent": "human"}, {"agent": "engine:"}]}`, "seat 2: engine players need the command")
+	testErr(`{"engine_time": "soon", "seats": [{"agent": "human"}, {"agent": "human"}]}`, "engine time")
+	testErr(`{"move_time": "5", "seats": [{"agent": "human"}, {"agent": "human"}]}`, "move time")
+	testErr(`{"fallback": "resign", "seats": [{"agent": "human"}, {"agent": "human"}]}`, "unknown fallback 'resign'")
+	testErr("{\n\"sets\": []}", "unknown field")
+	testErr("{\n\"seats\": [\n{\"agent\": 1}]}", "line 3")
+}
+
+func TestEngineSeat(t *testing.T) {
+	config, err := parseConfigFile([]byte(`{
+		"engine_time": "250ms",
+		"seats": [
+			{"agent": "random"},
+			{"agent": "Engine:./bots/Greedy --fast"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.PlayerTypes[1] != External || config.engineCommand(1) != "./bots/Greedy --fast" || config.engineCommand(0) != "" {
+		t.Fatal("seat 2 should run the engine, keeping the case of its command")
+	}
+
+	if config.EngineTime.Milliseconds() != 250 {
+		t.Fatalf("the engine time should be 250ms, was %s", config.EngineTime)
+	}
+
+	if config.agentDescription(1) != "Engine Greedy" || config.agentString(1) != "engine:./bots/Greedy --fast" {
+		t.Fatalf("seat 2 was described as %s (%s)", config.agentDescription(1), config.agentString(1))
+	}
+
+	// the command line overrides the file, and engines can play simulations
+	config, err = parseFlags([]string{"--simulate", "2", "--players", "2", "--seat", "2=engine:./bot", "--engine-time", "2s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.PlayerTypes[1] != External || config.EngineTime.Seconds() != 2 {
+		t.Fatal("seat 2 should be an engine with 2 seconds a move")
+	}
+
+	if _, err := parseFlags([]string{"--engine-time", "0s"}); err == nil {
+		t.Error("engines should need some time to move")
+	}
+}
+
+func TestMoveTime(t *testing.T) {
+	config, err := parseConfigFile([]byte(`{
+		"move_time": "5s",
+		"fallback": "heuristic",
+		"seats": [{"agent": "human"}, {"agent": "random"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.MoveTime.Seconds() != 5 || config.Fallback != ai.FallbackHeuristic {
+		t.Fatalf("expected 5s a move with the heuristic fallback, got %s with %s", config.MoveTime, config.Fallback)
+	}
+
+	config, err = parseFlags([]string{"--simulate", "1", "--move-time", "100ms", "--fallback", "random"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.MoveTime.Milliseconds() != 100 || config.Fallback != ai.FallbackRandom {
+		t.Fatalf("expected 100ms a move with the random fallback, got %s with %s", config.MoveTime, config.Fallback)
+	}
+
+	if _, err := parseFlags([]string{"--move-time", "-1s"}); err == nil {
+		t.Error("a negative move time should be an error")
+	}
+
+	if _, err := parseFlags([]string{"--fallback", "resign"}); err == nil {
+		t.Error("an unknown fallback should be an error")
+	}
+}
+
+func TestRenderer(t *testing.T) {
+	config, err := parseConfigFile([]byte(`{"render": "ANSI", "palette": "colorblind", "seats": [{"agent": "human"}, {"agent": "random"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renderer, err := config.renderer(os.Stdout)
+	if err != nil || renderer != (acquire.ANSIRenderer{Palette: acquire.PaletteColorBlind}) {
+		t.Fatalf("the game should be drawn with ansi in the color blind palette, got %+v %v", renderer, err)
+	}
+
+	// the command line overrides the file
+	config, err = parseFlags([]string{"--players", "2", "--render", "none"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if renderer, _ := config.renderer(os.Stdout); renderer != (acquire.NopRenderer{}) {
+		t.Fatalf("the game shouldn't be drawn, got %T", renderer)
+	}
+
+	// colors are only for terminals
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	config, _ = parseFlags([]string{"--players", "2", "--palette", "colorblind"})
+	if renderer, _ := config.renderer(file); renderer != (acquire.PlainRenderer{}) {
+		t.Errorf("the game should be drawn as plain text into a file, got %T", renderer)
+	}
+
+	if _, err := parseFlags([]string{"--render", "braille"}); err == nil || !strings.Contains(err.Error(), "or auto") {
+		t.Errorf("unknown renderers should be refused, got %v", err)
+	}
+	if _, err := parseFlags([]string{"--palette", "sepia"}); err == nil {
+		t.Error("unknown palettes should be refused")
+	}
+
+	testErr := `{"render": "svg", "seats": [{"agent": "human"}, {"agent": "human"}]}`
+	if _, err := parseConfigFile([]byte(testErr)); err == nil {
+		t.Error("unknown renderers should be refused in the file")
+	}
+}
+
+func TestTUI(t *testing.T) {
+	config, err := parseConfigFile([]byte(`{"tui": true, "seats": [{"agent": "human"}, {"agent": "random"}]}`))
+	if err != nil || !config.TUI {
+		t.Fatalf("the game should be played in the tui, got %+v %v", config, err)
+	}
+
+	if _, err := parseFlags([]string{"--simulate", "2", "--tui"}); err == nil {
+		t.Error("simulated games shouldn't be played in the tui")
+	}
+}
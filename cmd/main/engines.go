@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// engineCommand
+// the command line of the external engine in seat i, blank if it isn't one
+func (config *GameConfig) engineCommand(i int) string {
+	if i < len(config.EngineCommands) {
+		return config.EngineCommands[i]
+	}
+
+	return ""
+}
+
+func (config *GameConfig) setEngineCommand(i int, command string) {
+	if len(config.EngineCommands) < config.NumPlayers {
+		commands := make([]string, config.NumPlayers)
+		copy(commands, config.EngineCommands)
+		config.EngineCommands = commands
+	}
+
+	config.EngineCommands[i] = command
+}
+
+// engineLogs
+// the transcript files of the external engine seats (by seat index), which last for the whole run
+// so that every simulated game is written to the same file
+type engineLogs map[int]*os.File
+
+// openEngineLogs
+// creates seatN.log in the config's engine log directory for each external engine seat,
+// there are none when the directory isn't set
+func openEngineLogs(config *GameConfig) (engineLogs, error) {
+	logs := make(engineLogs)
+	if config.EngineLogDir == "" {
+		return logs, nil
+	}
+
+	err := os.MkdirAll(config.EngineLogDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, playerType := range config.PlayerTypes {
+		if playerType != External {
+			continue
+		}
+
+		file, err := os.Create(filepath.Join(config.EngineLogDir, fmt.Sprintf("seat%d.log", i+1)))
+		if err != nil {
+			logs.close()
+			return nil, err
+		}
+		logs[i] = file
+	}
+
+	return logs, nil
+}
+
+// writer
+// the transcript for seat i, nil if it doesn't have one
+func (logs engineLogs) writer(i int) io.Writer {
+	if file, ok := logs[i]; ok {
+		return file
+	}
+
+	return nil
+}
+
+func (logs engineLogs) close() {
+	for _, file := range logs {
+		file.Close()
+	}
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"acquire/internal/cli"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseFlags
+// builds a game config from the command line arguments.
+// returns nil (and no error) when no arguments were given, in which case the interactive menu should be used
+func parseFlags(args []string) (*GameConfig, error) {
+	flags := flag.NewFlagSet("acquire", flag.ContinueOnError)
+
+	var seats cli.Repeated
+	var names cli.Repeated
+	configPath := flags.String("config", "", "path to a json game setup file")
+	numPlayers := flags.Int("players", DefaultGameConfig.NumPlayers, "number of players [2-6]")
+	flags.Var(&seats, "seat", "seat setup as n=human, n=random, n=mcts:strength or n=engine:command (repeatable)")
+	flags.Var(&names, "name", "seat name as n=name (repeatable)")
+	seed := flags.Int64("seed", 0, "seed for the random number generator (0 = random)")
+	rulesPath := flags.String("rules", "", "path to a json file of rule variants")
+	quiet := flags.Bool("quiet", false, "don't narrate each action")
+	render := flags.String("render", renderAuto, "how to draw the game: "+renderAuto+" (ansi on a terminal, plain otherwise), "+strings.Join(acquire.RendererNames(), ", "))
+	useTUI := flags.Bool("tui", false, "play in a full screen terminal ui, picking moves with the arrow keys")
+	palette := flags.String("palette", "classic", "the colors of the chains when drawn with ansi: classic or colorblind")
+	save := flags.String("save", "", "path to write a record of the game to")
+	simulate := flags.Int("simulate", 0, "play this many AI only games without rendering, then summarize them")
+	treeDir := flags.String("trees", "", "directory to write the AI's search trees to, as graphviz and json")
+	treeDepth := flags.Int("tree-depth", 2, "how many levels of the search trees to write")
+	from := flags.String("from", "", "path to a position to start the game from, instead of a new game")
+	engineTime := flags.Duration("engine-time", DefaultGameConfig.EngineTime, "how long external engines get for each move")
+	engineLogs := flags.String("engine-logs", "", "directory to write the transcript of each external engine to")
+	moveTime := flags.Duration("move-time", 0, "how long seats that aren't human get for each move (0 = no limit)")
+	fallback := flags.String("fallback", "first", "what to play for a seat that runs out of time or fails: first, random or heuristic")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags.NFlag() == 0 {
+		return nil, nil
+	}
+
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument '%s'", flags.Arg(0))
+	}
+
+	isSet := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		isSet[f.Name] = true
+	})
+
+	// the rules are read first, since a position is read with the rules it's played by
+	var rules *acquire.Rules
+	if *rulesPath != "" {
+		fileRules, err := cli.ReadRules(*rulesPath)
+		if err != nil {
+			return nil, err
+		}
+		rules = &fileRules
+	}
+
+	// the position, when it's read to find the number of players, so that it isn't read again
+	var position string
+	var positionGame *acquire.Game
+
+	var config *GameConfig
+	if *configPath != "" {
+		if isSet["players"] {
+			return nil, errors.New("--players can't be used with --config, the number of seats comes from the file")
+		}
+
+		config, err = readConfigFile(*configPath)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// a position has its own number of players
+		if *from != "" && !isSet["players"] {
+			positionRules := acquire.DefaultRules
+			if rules != nil {
+				positionRules = *rules
+			}
+
+			position, positionGame, err = readPosition(*from, positionRules)
+			if err != nil {
+				return nil, err
+			}
+			*numPlayers = len(positionGame.PlayerSlice())
+		}
+
+		if *numPlayers < 2 || *numPlayers > acquire.MAX_PLAYERS {
+			return nil, fmt.Errorf("number of players must be within 2-%d, was %d", acquire.MAX_PLAYERS, *numPlayers)
+		}
+
+		config = newGameConfig(*numPlayers)
+
+		// simulations can't have anyone waiting on input, so seats default to random play
+		if *simulate > 0 {
+			for i := range config.PlayerTypes {
+				config.PlayerTypes[i] = Random
+			}
+		}
+	}
+
+	// anything given on the command line overrides the config file
+	if isSet["seed"] {
+		config.Seed = *seed
+	}
+	if isSet["quiet"] {
+		config.Quiet = *quiet
+	}
+	if isSet["render"] {
+		config.Render, err = checkRender(*render)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isSet["tui"] {
+		config.TUI = *useTUI
+	}
+	if isSet["palette"] {
+		config.Palette, err = acquire.ParsePalette(*palette)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isSet["save"] {
+		config.Save = *save
+	}
+	if isSet["simulate"] {
+		config.Simulate = *simulate
+	}
+	if isSet["from"] {
+		config.From = *from
+	}
+	if isSet["trees"] {
+		config.TreeDir = *treeDir
+	}
+	config.TreeDepth = *treeDepth
+	if isSet["engine-time"] {
+		config.EngineTime = *engineTime
+	}
+	if isSet["engine-logs"] {
+		config.EngineLogDir = *engineLogs
+	}
+	if isSet["move-time"] {
+		config.MoveTime = *moveTime
+	}
+	if isSet["fallback"] {
+		config.Fallback, err = ai.ParseFallback(*fallback)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.TreeDepth < 0 {
+		return nil, fmt.Errorf("the tree depth can't be negative, was %d", config.TreeDepth)
+	}
+
+	if config.EngineTime < time.Millisecond {
+		return nil, fmt.Errorf("engines need at least a millisecond for each move, were given %s", config.EngineTime)
+	}
+
+	if config.MoveTime < 0 {
+		return nil, fmt.Errorf("the move time can't be negative, was %s", config.MoveTime)
+	}
+
+	for _, seat := range seats {
+		err := config.setSeat(seat)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range names {
+		err := config.setName(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rules != nil {
+		config.Rules = *rules
+	}
+
+	if config.From != "" {
+		err = config.loadPosition(position, positionGame)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Simulate < 0 {
+		return nil, fmt.Errorf("can't simulate %d games", config.Simulate)
+	}
+
+	if config.Simulate > 0 {
+		if config.Save != "" {
+			return nil, errors.New("simulated games aren't saved, leave out --save")
+		}
+
+		if config.TreeDir != "" {
+			return nil, errors.New("search trees aren't written for simulated games, leave out --trees")
+		}
+
+		if config.TUI {
+			return nil, errors.New("simulated games aren't played in the tui, leave out --tui")
+		}
+
+		for i, playerType := range config.PlayerTypes {
+			if playerType == Human {
+				return nil, fmt.Errorf("seat %d is human, simulations can only be played by AI (try --seat %d=random)", i+1, i+1)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// newGameConfig
+// a config for some number of players, seated the same as DefaultGameConfig where possible
+// and as medium strength AI otherwise
+func newGameConfig(numPlayers int) *GameConfig {
+	config := &GameConfig{
+		NumPlayers:        numPlayers,
+		PlayerTypes:       make([]PlayerType, numPlayers),
+		AIPlayerStrengths: make([]int, numPlayers),
+		EngineTime:        DefaultGameConfig.EngineTime,
+		Rules:             DefaultGameConfig.Rules,
+	}
+
+	for i := 0; i < numPlayers; i++ {
+		if i < DefaultGameConfig.NumPlayers {
+			config.PlayerTypes[i] = DefaultGameConfig.PlayerTypes[i]
+			config.AIPlayerStrengths[i] = DefaultGameConfig.AIPlayerStrengths[i]
+			continue
+		}
+
+		config.PlayerTypes[i] = AI
+		config.AIPlayerStrengths[i] = ai.DefaultStrength
+	}
+
+	return config
+}
+
+// setSeat
+// applies a seat string of the form 'n=human', 'n=random', 'n=mcts:strength' or 'n=engine:command' to the config
+func (config *GameConfig) setSeat(seat string) error {
+	numStr, agentStr, ok := strings.Cut(seat, "=")
+	if !ok {
+		return fmt.Errorf("seat '%s' should be of the form n=type", seat)
+	}
+
+	num, err := strconv.Atoi(numStr)
+	if err != nil || num < 1 || num > config.NumPlayers {
+		return fmt.Errorf("seat '%s' must be numbered within 1-%d", seat, config.NumPlayers)
+	}
+
+	err = config.setAgent(num-1, agentStr)
+	if err != nil {
+		return fmt.Errorf("seat %d: %w", num, err)
+	}
+
+	return nil
+}
+
+// setName
+// applies a name string of the form 'n=name' to the config
+func (config *GameConfig) setName(name string) error {
+	numStr, nameStr, ok := strings.Cut(name, "=")
+	if !ok {
+		return fmt.Errorf("name '%s' should be of the form n=name", name)
+	}
+
+	num, err := strconv.Atoi(numStr)
+	if err != nil || num < 1 || num > config.NumPlayers {
+		return fmt.Errorf("name '%s' must be numbered within 1-%d", name, config.NumPlayers)
+	}
+
+	if len(config.PlayerNames) < config.NumPlayers {
+		names := make([]string, config.NumPlayers)
+		copy(names, config.PlayerNames)
+		config.PlayerNames = names
+	}
+
+	config.PlayerNames[num-1] = strings.TrimSpace(nameStr)
+
+	return nil
+}
+
+// setAgent
+// seats the agent described as in ai.ParseSeat, e.g. 'human', 'mcts:500' or 'engine:./bot --fast', in seat i
+func (config *GameConfig) setAgent(i int, agent string) error {
+	seat, err := ai.ParseSeat(agent)
+	if err != nil {
+		return err
+	}
+
+	switch seat.Type {
+	case ai.SeatHuman:
+		config.PlayerTypes[i] = Human
+	case ai.SeatRandom:
+		config.PlayerTypes[i] = Random
+	case ai.SeatMCTS:
+		config.PlayerTypes[i] = AI
+	case ai.SeatEngine:
+		config.PlayerTypes[i] = External
+	default:
+		return errors.New("remote players can only join a server, see cmd/server")
+	}
+
+	config.AIPlayerStrengths[i] = seat.Strength
+	config.setEngineCommand(i, seat.Command)
+
+	return nil
+}
+
+// seat
+// what plays seat i, as ai.ParseSeat would read it
+func (config *GameConfig) seat(i int) ai.Seat {
+	switch config.PlayerTypes[i] {
+	case Human:
+		return ai.Seat{Type: ai.SeatHuman}
+	case Random:
+		return ai.Seat{Type: ai.SeatRandom}
+	case External:
+		return ai.Seat{Type: ai.SeatEngine, Command: config.engineCommand(i)}
+	default:
+		return ai.Seat{Type: ai.SeatMCTS, Strength: config.AIPlayerStrengths[i]}
+	}
+}
+
+// checkRender
+// the renderer name in the form the config keeps it, or an error when there's no such renderer
+func checkRender(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == renderAuto {
+		return name, nil
+	}
+
+	_, err := acquire.ParseRenderer(name, acquire.PaletteClassic)
+	if err != nil {
+		return "", fmt.Errorf("%w or %s", err, renderAuto)
+	}
+
+	return name, nil
+}
+
+// readPosition
+// reads a position file, returning its contents along with the game it describes
+func readPosition(path string, rules acquire.Rules) (string, *acquire.Game, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	game, err := acquire.ParsePositionWithRules(string(data), rules)
+	if err != nil {
+		return "", nil, fmt.Errorf("position %s: %w", path, err)
+	}
+
+	return string(data), game, nil
+}
+
+// loadPosition
+// reads the position the config starts from, checking that it's playable with the config's seats and rules.
+// when it's already been read (with the config's rules), its contents and game are passed in instead
+func (config *GameConfig) loadPosition(position string, game *acquire.Game) error {
+	if game == nil {
+		var err error
+		position, game, err = readPosition(config.From, config.Rules)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(game.PlayerSlice()) != config.NumPlayers {
+		return fmt.Errorf("position %s has %d players, but there are %d seats", config.From, len(game.PlayerSlice()), config.NumPlayers)
+	}
+
+	if game.IsTerminal() {
+		return fmt.Errorf("position %s is already over", config.From)
+	}
+
+	config.Position = position
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"acquire/internal/ai"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPosition = `
+players: 3
+turn: 1
+player 2: $1200, tiles 3A, stocks W4
+   1  2  3  4  5  6  7  8  9  10 11 12
+a  W  W  □  T  T  □  □  □  □  □  □  □
+`
+
+func TestFromPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "position.txt")
+	err := os.WriteFile(path, []byte(testPosition), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := parseFlags([]string{"--from", path, "--seat", "1=random", "--name", "2=Bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.NumPlayers != 3 || config.Position != testPosition {
+		t.Fatal("the number of players should come from the position")
+	}
+
+	game, agents, err := setupGame(config, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 3 {
+		t.Fatalf("there should be an agent for each of the 3 players, there were %d", len(agents))
+	}
+
+	bob := game.CurrentPlayer()
+	if bob.Name() != "Bob" || bob.Money != 1200 || bob.Stocks[0] != 4 {
+		t.Fatal("the game should start from the position, with Bob to play")
+	}
+
+	_, err = parseFlags([]string{"--from", path, "--players", "4"})
+	if err == nil || !strings.Contains(err.Error(), "has 3 players, but there are 4 seats") {
+		t.Fatalf("expected the seats not matching the position to be an error, got %v", err)
+	}
+}
+
+// TestFromFinishedPosition
+// a game can't start from a position which is already over
+func TestFromFinishedPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "position.txt")
+	err := os.WriteFile(path, []byte(testPosition+`over: "no tiles left"`+"\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = parseFlags([]string{"--from", path})
+	if err == nil || !strings.Contains(err.Error(), "is already over") {
+		t.Fatalf("expected the finished position to be refused, got %v", err)
+	}
+}
+
+// TestSetupGameErrors
+// a position which doesn't read is an error rather than a panic
+func TestSetupGameErrors(t *testing.T) {
+	config := newGameConfig(2)
+	config.From = "broken.txt"
+	config.Position = "players: 2\na  W"
+
+	_, _, err := setupGame(config, nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "position broken.txt") {
+		t.Fatalf("expected the position not reading to be an error, got %v", err)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	config, err := parseFlags(nil)
+	if config != nil || err != nil {
+		t.Fatalf("no flags should fall back to the menu, got %+v %v", config, err)
+	}
+
+	config, err = parseFlags([]string{"--players", "3", "--seat", "1=human", "--seat", "2=MCTS:200", "--seat", "3=random", "--seed", "7", "--quiet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.NumPlayers != 3 || config.Seed != 7 || !config.Quiet {
+		t.Fatalf("the flags weren't read, got %+v", config)
+	}
+
+	expectedTypes := []PlayerType{Human, AI, Random}
+	for i, playerType := range expectedTypes {
+		if config.PlayerTypes[i] != playerType {
+			t.Errorf("seat %d should be %v, was %v", i+1, playerType, config.PlayerTypes[i])
+		}
+	}
+	if config.AIPlayerStrengths[1] != 200 {
+		t.Errorf("seat 2 should search 200 rounds, got %d", config.AIPlayerStrengths[1])
+	}
+
+	// seats which aren't given keep the defaults, and extra seats are AI
+	config, err = parseFlags([]string{"--players", "6", "--seat", "2=random"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.PlayerTypes[1] != Random || config.PlayerTypes[5] != AI || config.AIPlayerStrengths[5] != ai.DefaultStrength {
+		t.Fatalf("unexpected seats %v %v", config.PlayerTypes, config.AIPlayerStrengths)
+	}
+
+	// simulations default to random play
+	config, err = parseFlags([]string{"--simulate", "3", "--players", "4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, playerType := range config.PlayerTypes {
+		if playerType != Random {
+			t.Errorf("simulated seat %d should play randomly, was %v", i+1, playerType)
+		}
+	}
+}
+
+func TestParseFlagsErrors(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--players", "1"}, "within 2-6"},
+		{[]string{"--players", "7"}, "within 2-6"},
+		{[]string{"--players", "2", "extra"}, "unexpected argument 'extra'"},
+		{[]string{"--seat", "human"}, "should be of the form n=type"},
+		{[]string{"--players", "3", "--seat", "4=human"}, "numbered within 1-3"},
+		{[]string{"--seat", "0=human"}, "numbered within 1-"},
+		{[]string{"--seat", "1=wizard"}, "unknown agent type 'wizard'"},
+		{[]string{"--seat", "1=mcts:-5"}, "positive number of search rounds"},
+		{[]string{"--seat", "1=human:3"}, "don't take a strength"},
+		{[]string{"--seat", "1=engine:"}, "need the command to run"},
+		{[]string{"--seat", "1=remote"}, "remote players can only join a server"},
+		{[]string{"--name", "Bob"}, "should be of the form n=name"},
+		{[]string{"--simulate", "2", "--seat", "1=human"}, "seat 1 is human"},
+		{[]string{"--simulate", "-1"}, "can't simulate -1 games"},
+	}
+
+	for _, test := range tests {
+		_, err := parseFlags(test.args)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%v: expected an error containing %q, got %v", test.args, test.expected, err)
+		}
+	}
+}
+
+func TestRulesFlag(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "rules.json")
+	err := os.WriteFile(path, []byte(`{"safe_chain_size": 12}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := parseFlags([]string{"--rules", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Rules.SafeChainSize != 12 || config.Rules.StartingMoney != 6000 || config.Rules.EndChainSize != 41 {
+		t.Fatalf("rules left out of the file should keep their defaults, got %+v", config.Rules)
+	}
+
+	invalid := map[string]string{
+		"unknown.json": `{"starting_cash": 1}`,
+		"unsafe.json":  `{"safe_chain_size": 50}`,
+		"broken.json":  `{"safe_chain_size":`,
+	}
+	for name, contents := range invalid {
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := parseFlags([]string{"--rules", path}); err == nil {
+			t.Errorf("the rules in %s should be refused", name)
+		}
+	}
+}
@@ -3,71 +3,263 @@ package main
 import (
 	"acquire/internal/acquire"
 	"acquire/internal/ai"
+	"acquire/internal/tui"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"golang.org/x/term"
+	"math/rand"
+	"os"
 )
 
 func main() {
-	config := menu()
+	config, err := parseFlags(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// no flags given, fall back to asking
+	if config == nil {
+		config = menu()
+	}
+
+	if config.Simulate > 0 {
+		runSimulation(config)
+		return
+	}
+
 	runGame(config)
 }
 
 func runGame(config *GameConfig) *acquire.Game {
-	game := acquire.NewGame()
+	if config.Seed != 0 {
+		rand.Seed(config.Seed)
+	}
 
-	agents := make(map[int]ai.IAgent)
+	if config.TreeDir != "" {
+		err := os.MkdirAll(config.TreeDir, 0755)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
 
-	// unset unused players
-	for i := config.NumPlayers; i < len(game.Players); i++ {
-		game.Players[i].Id = 0
+	logs, err := openEngineLogs(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+	defer logs.close()
 
-	// enabled from config
-	for i := range config.PlayerTypes {
-		if config.PlayerTypes[i] == Human {
-			agents[game.Players[i].Id] = ai.NewHumanAgent()
+	// quitting the tui stops the game, whoever's turn it is
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	var ui *tui.UI
+	if config.TUI {
+		ui, err = tui.Open(config.Palette, func() { cancel(tui.ErrQuit) })
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
-		if config.PlayerTypes[i] == AI {
-			strength := config.AIPlayerStrengths[i]
-			agents[game.Players[i].Id] = ai.NewSmartAgent(strength)
+	}
+
+	// narrates the game, in the tui's log when there is one
+	narrate := func(line string) {
+		if ui != nil {
+			ui.Log(line)
+		} else if !config.Quiet {
+			fmt.Println(line)
 		}
 	}
 
-	for !game.IsTerminal() {
+	var record *gameRecord
+	report := func(incident ai.Incident) {
+		record.Incidents = append(record.Incidents, incidentRecord{
+			Action: len(record.Actions),
+			Player: incident.PlayerId,
+			Reason: incident.Reason,
+		})
 
-		currentPlayerType := config.PlayerTypes[game.ActivePlayer().Id-1]
-		agent := agents[game.ActivePlayer().Id]
-		actions := game.GetActions()
+		narrate(fmt.Sprintf("%s %s, falling back to the %s action.", incident.Agent, incident.Reason, config.Fallback.String()))
+	}
 
-		if currentPlayerType == Human {
-			// render before play
-			acquire.Render(game)
-		}
+	renderer, err := config.renderer(os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-		action, err := agent.SelectAction(game, actions)
+	render := func(game *acquire.Game) {
+		err := renderer.Render(os.Stdout, game)
 		if err != nil {
-			panic(err)
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	game, agents, err := setupGame(config, logs, report, func(err error) {
+		narrate(err.Error())
+	})
+	if err != nil {
+		if ui != nil {
+			ui.Close()
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	record = newGameRecord(config, game)
+
+	// the ids of the human seats, who pick their moves in the tui instead of at prompts
+	humans := make(map[int]bool)
+	for i, playerType := range config.PlayerTypes {
+		if playerType != Human {
+			continue
+		}
+
+		id := game.Players[i].Id
+		humans[id] = true
+		if ui != nil {
+			agents[id] = ui.Seat(agents[id].Name())
+		}
+	}
+
+	game, err = ai.Play(ctx, game, agents, ai.PlayHooks{
+		BeforeSelect: func(game *acquire.Game, agent ai.Agent) {
+			if ui != nil {
+				ui.Show(game)
+				return
+			}
+
+			if humans[game.ActivePlayer().Id] {
+				// render before play
+				render(game)
+			}
+		},
+		OnAction: func(event ai.Event) {
+			// describe play
+			record.Actions = append(record.Actions, event.Description)
+
+			if ui != nil {
+				ui.Event(event)
+			} else if !config.Quiet {
+				fmt.Println(event.Description)
+			}
+		},
+	})
+
+	if ui != nil {
+		if err == nil {
+			ui.Finish(game)
 		}
 
-		// describe play
-		if _action, ok := action.(acquire.IAction); ok {
-			fmt.Println(_action.String(game))
+		closeErr := ui.Close()
+		if err == nil {
+			err = closeErr
 		}
+	}
 
-		newGame, _ := game.ApplyAction(action)
-		game = newGame.(*acquire.Game)
+	if errors.Is(err, context.Canceled) {
+		err = context.Cause(ctx)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	// render final board state
-	acquire.Render(game)
+	render(game)
 
 	fmt.Println()
-	reason, end := game.CanEnd()
-	game2 := game
-	game2.Computed = acquire.NewComputed(game2)
-	if !end {
-		reason = "Game was forced to end."
+	fmt.Println("End Reason: " + game.EndReason)
+
+	if config.Save != "" {
+		record.finish(game)
+		err = record.write(config.Save)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "could not save the game record: "+err.Error())
+		}
 	}
-	fmt.Println("End Reason: " + reason)
 
 	return game
 }
+
+// the renderer which draws in color on a terminal, and plain text anywhere else
+const renderAuto = "auto"
+
+// renderer
+// the renderer the config asks for, to draw on out. auto falls back to plain text when out isn't a terminal,
+// or when asked not to use colors (see no-color.org)
+func (config *GameConfig) renderer(out *os.File) (acquire.Renderer, error) {
+	name := config.Render
+	if name == "" || name == renderAuto {
+		name = "plain"
+		if term.IsTerminal(int(out.Fd())) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" {
+			name = "ansi"
+		}
+	}
+
+	return acquire.ParseRenderer(name, config.Palette)
+}
+
+// setupGame
+// creates a new game (or the game at the config's position) seated according to the config,
+// along with the agents playing each player (by id). the config's seats are the game's players in order,
+// whatever their ids. external engines write their transcripts to the logs,
+// and the moves played for seats which run out of time or fail are reported (when report isn't nil).
+// problems which don't stop the game, such as not being able to write a search tree, are passed to warn
+func setupGame(config *GameConfig, logs engineLogs, report func(ai.Incident), warn func(error)) (*acquire.Game, map[int]ai.Agent, error) {
+	var game *acquire.Game
+	if config.Position != "" {
+		var err error
+		game, err = acquire.ParsePositionWithRules(config.Position, config.Rules)
+		if err != nil {
+			return nil, nil, fmt.Errorf("position %s: %w", config.From, err)
+		}
+	} else {
+		game = acquire.NewGameWithRules(config.Rules)
+	}
+
+	agents := make(map[int]ai.Agent)
+
+	// unset unused players
+	for i := config.NumPlayers; i < len(game.Players); i++ {
+		game.Players[i].Id = 0
+	}
+
+	// enabled from config
+	for i := range config.PlayerTypes {
+		name := config.agentDescription(i)
+		err := game.SetPlayerIdentity(game.Players[i].Id, config.playerName(i), name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if config.PlayerTypes[i] == Human {
+			agents[game.Players[i].Id] = ai.Adapt(ai.NewHumanAgent(), name)
+		}
+		if config.PlayerTypes[i] == AI {
+			strength := config.AIPlayerStrengths[i]
+			agent := ai.NewSmartAgent(strength)
+			if config.TreeDir != "" {
+				agent.ExportTrees(config.TreeDir, config.TreeDepth, warn)
+			}
+			agents[game.Players[i].Id] = ai.Adapt(agent, name)
+		}
+		if config.PlayerTypes[i] == Random || config.PlayerTypes[i] == External {
+			agents[game.Players[i].Id] = config.seat(i).NewAgent(config.EngineTime, logs.writer(i))
+		}
+
+		// humans can't be hurried, but anyone else can be played for when they're stuck
+		if config.PlayerTypes[i] != Human {
+			id := game.Players[i].Id
+			agents[id] = ai.WithTimeLimit(agents[id], config.MoveTime, config.Fallback, report)
+		}
+	}
+
+	return game, agents, nil
+}
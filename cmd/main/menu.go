@@ -1,10 +1,13 @@
 package main
 
 import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type PlayerType int
@@ -12,18 +15,68 @@ type PlayerType int
 const (
 	Human PlayerType = iota
 	AI
+	Random
+	External
 )
 
 type GameConfig struct {
 	NumPlayers        int
 	PlayerTypes       []PlayerType
 	AIPlayerStrengths []int
+
+	// the command line of each external engine seat, blank for the other seats
+	EngineCommands []string
+	// how long external engines get to think about each move
+	EngineTime time.Duration
+	// where to write the transcript of each external engine seat, blank for nowhere
+	EngineLogDir string
+
+	// how long each seat that isn't human gets to pick a move (no limit when zero), and what
+	// to play for them when they take too long or fail (see ai.WithTimeLimit)
+	MoveTime time.Duration
+	Fallback ai.Fallback
+
+	// optional, players are known by their number when left blank
+	PlayerNames []string
+
+	// seeds the random number generator, zero leaves it randomly seeded
+	Seed  int64
+	Rules acquire.Rules
+
+	// don't narrate each action as it is played
+	Quiet bool
+
+	// how the game is drawn for human seats and once it's over (see renderer), blank for auto,
+	// and the colors it's drawn in
+	Render  string
+	Palette acquire.Palette
+
+	// play in a full screen terminal ui (see tui.UI) instead of drawing the game and prompting line by line
+	TUI bool
+
+	// where to write a record of the game once it is over, blank for nowhere
+	Save string
+
+	// the path of a position to start from (see acquire.ParsePosition), blank for a new game.
+	// Position is the contents of the file, read by loadPosition
+	From     string
+	Position string
+
+	// where the AI seats write the search tree behind each of their moves (see ai.SmartAgent.ExportTrees),
+	// blank for nowhere
+	TreeDir   string
+	TreeDepth int
+
+	// when set, this many games are played headless and summarized, instead of playing one game
+	Simulate int
 }
 
 var DefaultGameConfig = GameConfig{
 	NumPlayers:        4,
 	PlayerTypes:       []PlayerType{Human, AI, AI, AI},
 	AIPlayerStrengths: []int{0, 250, 500, 750},
+	EngineTime:        time.Second,
+	Rules:             acquire.DefaultRules,
 }
 
 func menu() *GameConfig {
@@ -31,6 +84,8 @@ func menu() *GameConfig {
 	fmt.Println()
 
 	var config GameConfig
+	config.Rules = acquire.DefaultRules
+	config.EngineTime = DefaultGameConfig.EngineTime
 
 	fmt.Print("Num Players? [2-6]: ")
 	config.NumPlayers = getBoundedInput("Num Players? [2-6]: ", 2, 6)
@@ -56,12 +111,12 @@ func getBoundedInput(prompt string, min int, max int) int {
 		inputInt, err := getInputInt()
 
 		if err != nil {
-			fmt.Println("\nInvalid Input, Try Again.\n")
+			fmt.Print("\nInvalid Input, Try Again.\n\n")
 			continue
 		}
 
 		if inputInt < min || inputInt > max {
-			fmt.Println("\nInvalid Input, Try Again.\n")
+			fmt.Print("\nInvalid Input, Try Again.\n\n")
 			continue
 		}
 
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"acquire/internal/acquire"
+	"encoding/json"
+	"os"
+)
+
+// gameRecord
+// what gets written to the save path once a game is over
+type gameRecord struct {
+	Seed      int64         `json:"seed"`
+	Rules     acquire.Rules `json:"rules"`
+	Position  string        `json:"position,omitempty"`
+	Seats     []seatRecord  `json:"seats"`
+	Actions   []string      `json:"actions"`
+	EndReason string        `json:"end_reason"`
+	Winners   []int         `json:"winners"`
+
+	// the moves which were played for a seat, because it ran out of time or failed
+	Incidents []incidentRecord `json:"incidents,omitempty"`
+}
+
+type incidentRecord struct {
+	// the index of the action in Actions
+	Action int    `json:"action"`
+	Player int    `json:"player"`
+	Reason string `json:"reason"`
+}
+
+type seatRecord struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Agent       string `json:"agent"`
+	Description string `json:"description"`
+	NetWorth    int    `json:"net_worth"`
+}
+
+func newGameRecord(config *GameConfig, game *acquire.Game) *gameRecord {
+	record := &gameRecord{
+		Seed:     config.Seed,
+		Rules:    config.Rules,
+		Position: config.Position,
+		Seats:    make([]seatRecord, config.NumPlayers),
+		Actions:  make([]string, 0),
+	}
+
+	for i := range record.Seats {
+		player := game.Players[i]
+		record.Seats[i] = seatRecord{
+			Id:          player.Id,
+			Name:        player.Name(),
+			Agent:       config.agentString(i),
+			Description: player.Agent,
+		}
+	}
+
+	return record
+}
+
+// finish
+// fills in the results of a finished game
+func (record *gameRecord) finish(game *acquire.Game) {
+	record.EndReason = game.EndReason
+
+	for i := range record.Seats {
+		player := game.GetPlayerById(record.Seats[i].Id)
+		record.Seats[i].NetWorth = player.NetWorth(game)
+	}
+
+	record.Winners = make([]int, 0)
+	for _, playerId := range game.Winners() {
+		record.Winners = append(record.Winners, int(playerId))
+	}
+}
+
+func (record *gameRecord) write(path string) error {
+	data, err := json.MarshalIndent(record, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// playerName
+// the configured name of the player in seat i, blank if they weren't given one
+func (config *GameConfig) playerName(i int) string {
+	if i < len(config.PlayerNames) {
+		return config.PlayerNames[i]
+	}
+
+	return ""
+}
+
+// agentDescription
+// a human friendly version of agentString, e.g. "MCTS-500"
+func (config *GameConfig) agentDescription(i int) string {
+	return config.seat(i).Description()
+}
+
+// agentString
+// the agent in seat i as ai.ParseSeat reads it
+func (config *GameConfig) agentString(i int) string {
+	return config.seat(i).String()
+}
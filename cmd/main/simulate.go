@@ -0,0 +1,194 @@
+package main
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+)
+
+// simulationSummary
+// totals collected over a batch of headless games
+type simulationSummary struct {
+	games    int
+	duration time.Duration
+	turns    int
+
+	// why each game ended, and why it could have been declared over (if it could have been)
+	endReasons    map[string]int
+	canEndReasons map[string]int
+
+	// number of games won by each seat, ties count as a win for everyone tied
+	wins         []int
+	seatNames    []string
+	chainFounded map[acquire.Hotel]int
+
+	// number of moves played for each seat by the fallback, because it ran out of time or failed
+	incidents []int
+}
+
+func runSimulation(config *GameConfig) *simulationSummary {
+	if config.Seed != 0 {
+		rand.Seed(config.Seed)
+	}
+
+	summary := &simulationSummary{
+		endReasons:    make(map[string]int),
+		canEndReasons: make(map[string]int),
+		wins:          make([]int, config.NumPlayers),
+		chainFounded:  make(map[acquire.Hotel]int),
+		incidents:     make([]int, config.NumPlayers),
+	}
+
+	// report progress roughly every tenth of the way through
+	progressInterval := config.Simulate / 10
+	if progressInterval < 1 {
+		progressInterval = 1
+	}
+
+	logs, err := openEngineLogs(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer logs.close()
+
+	start := time.Now()
+	for i := 0; i < config.Simulate; i++ {
+		game := summary.simulateGame(config, logs)
+
+		if summary.seatNames == nil {
+			summary.seatNames = make([]string, config.NumPlayers)
+			for s := range summary.seatNames {
+				summary.seatNames[s] = game.Players[s].Description()
+			}
+		}
+
+		if !config.Quiet && (i+1)%progressInterval == 0 {
+			fmt.Printf("Simulated %d/%d games (%s)\n", i+1, config.Simulate, time.Since(start).Round(time.Millisecond))
+		}
+	}
+	summary.duration = time.Since(start)
+
+	summary.print()
+
+	return summary
+}
+
+// simulateGame
+// plays one game to the end without any output, adding its results to the summary
+func (summary *simulationSummary) simulateGame(config *GameConfig, logs engineLogs) *acquire.Game {
+	game, agents, err := setupGame(config, logs, func(incident ai.Incident) {
+		summary.incidents[incident.PlayerId-1]++
+	}, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	game, err = ai.Play(context.Background(), game, agents, ai.PlayHooks{
+		OnAction: func(event ai.Event) {
+			if found, ok := event.Action.(acquire.Action_PickHotelToFound); ok {
+				summary.chainFounded[found.Hotel]++
+			}
+		},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	summary.games++
+	summary.turns += game.Turn
+	summary.endReasons[game.EndReason]++
+
+	canEndReason, canEnd := game.CanEnd()
+	if canEnd {
+		summary.canEndReasons[canEndReason]++
+	}
+
+	for _, winner := range game.Winners() {
+		summary.wins[int(winner)-1]++
+	}
+
+	return game
+}
+
+func (summary *simulationSummary) print() {
+	if summary.games < 1 {
+		return
+	}
+
+	games := float64(summary.games)
+
+	fmt.Println()
+	fmt.Printf("Simulated %d games in %s (%.2f games/sec)\n",
+		summary.games,
+		summary.duration.Round(time.Millisecond),
+		games/summary.duration.Seconds(),
+	)
+	fmt.Printf("Average turns: %.1f\n", float64(summary.turns)/games)
+
+	fmt.Println()
+	fmt.Println("End reasons:")
+	printCounts(summary.endReasons, summary.games)
+
+	fmt.Println()
+	fmt.Println("Could have been declared over:")
+	printCounts(summary.canEndReasons, summary.games)
+
+	fmt.Println()
+	fmt.Println("Wins by seat:")
+	for i, wins := range summary.wins {
+		fmt.Printf("%6d %5.1f%%  %d: %s\n", wins, 100*float64(wins)/games, i+1, summary.seatNames[i])
+	}
+
+	totalFounded := 0
+	for _, n := range summary.chainFounded {
+		totalFounded += n
+	}
+
+	fmt.Println()
+	fmt.Printf("Chains founded (%.2f per game):\n", float64(totalFounded)/games)
+	for _, hotel := range acquire.HotelChainList {
+		fmt.Printf("%6d  %s\n", summary.chainFounded[hotel], hotel.String())
+	}
+
+	totalIncidents := 0
+	for _, n := range summary.incidents {
+		totalIncidents += n
+	}
+	if totalIncidents == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Moves played by the fallback:")
+	for i, n := range summary.incidents {
+		fmt.Printf("%6d  %d: %s\n", n, i+1, summary.seatNames[i])
+	}
+}
+
+// printCounts
+// prints the counts in a map from most to least common, with their share of the total
+func printCounts(counts map[string]int, total int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] == counts[keys[j]] {
+			return keys[i] < keys[j]
+		}
+		return counts[keys[i]] > counts[keys[j]]
+	})
+
+	for _, k := range keys {
+		fmt.Printf("%6d %5.1f%%  %s\n", counts[k], 100*float64(counts[k])/float64(total), k)
+	}
+}
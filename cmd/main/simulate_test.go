@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// the ways a game can end, as recorded in EndReason
+var endReasons = map[string]bool{
+	"no tiles left":                          true,
+	"no one had any moves left to play":      true,
+	"a player has declared the game is over": true,
+}
+
+func TestSimulation(t *testing.T) {
+	simulate := func() *simulationSummary {
+		config, err := parseFlags([]string{"--simulate", "4", "--players", "3", "--seed", "29", "--quiet"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runSimulation(config)
+	}
+
+	summary := simulate()
+	if summary.games != 4 || summary.turns < 4 {
+		t.Fatalf("4 games should have been played, got %d games of %d turns", summary.games, summary.turns)
+	}
+
+	ended := 0
+	for reason, n := range summary.endReasons {
+		if !endReasons[reason] {
+			t.Errorf("unexpected end reason %q", reason)
+		}
+		ended += n
+	}
+	if ended != summary.games {
+		t.Errorf("every game should have an end reason, %d of %d did", ended, summary.games)
+	}
+
+	// ties count for everyone tied, so there's at least one win per game
+	wins := 0
+	for _, n := range summary.wins {
+		wins += n
+	}
+	if wins < summary.games {
+		t.Errorf("every game should have a winner, there were %d wins in %d games", wins, summary.games)
+	}
+
+	if len(summary.seatNames) != 3 || !strings.Contains(summary.seatNames[0], "Random") {
+		t.Errorf("the seats should be described by who played them, got %v", summary.seatNames)
+	}
+
+	// the same seed plays the same games
+	again := simulate()
+	if again.turns != summary.turns || len(again.endReasons) != len(summary.endReasons) {
+		t.Errorf("seeded simulations should repeat, got %d turns then %d", summary.turns, again.turns)
+	}
+	for reason, n := range summary.endReasons {
+		if again.endReasons[reason] != n {
+			t.Errorf("seeded simulations should end the same way, %q happened %d then %d times", reason, n, again.endReasons[reason])
+		}
+	}
+}
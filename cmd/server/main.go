@@ -0,0 +1,239 @@
+package main
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"acquire/internal/cli"
+	"acquire/internal/server"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// server
+// hosts games for remote players over tcp (see the server package for the protocol), e.g. for two people
+// playing against an AI:
+//
+//	go run ./cmd/server --addr :7777 --players 3 --seat 3=mcts:500
+//
+// or with --http, serves the http api (see server.API) instead, where games are created by the players,
+// along with a page for playing them in a browser
+func main() {
+	err := run(os.Args[1:], os.Stdout, net.Listen)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+type options struct {
+	addr    string
+	http    string
+	games   int
+	quiet   bool
+	rules   acquire.Rules
+	players int
+	// what plays each seat: remote, random, mcts:n or engine:command
+	seats []ai.Seat
+
+	engineTime time.Duration
+	moveTime   time.Duration
+	fallback   ai.Fallback
+}
+
+// run
+// hosts the games, listening for each one with listen (net.Listen outside of tests)
+func run(args []string, out io.Writer, listen func(network, address string) (net.Listener, error)) error {
+	opts, err := parseOptions(args)
+	if err != nil {
+		return err
+	}
+
+	if opts.http != "" {
+		return opts.serveHTTP(out, listen)
+	}
+
+	for i := 0; i < opts.games; i++ {
+		table, err := opts.newTable(out)
+		if err != nil {
+			return err
+		}
+
+		listener, err := listen("tcp", opts.addr)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "Game %d/%d, waiting for %d remote players on %s\n", i+1, opts.games, opts.numRemote(), listener.Addr())
+
+		game, err := table.Serve(context.Background(), listener)
+		if err != nil {
+			return err
+		}
+
+		printResult(out, game)
+	}
+
+	return nil
+}
+
+func parseOptions(args []string) (options, error) {
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+
+	var seats cli.Repeated
+	opts := options{}
+	flags.StringVar(&opts.addr, "addr", "localhost:7777", "address to listen for players on")
+	flags.StringVar(&opts.http, "http", "", "address to serve the http api and browser ui on, instead of hosting tcp games")
+	flags.IntVar(&opts.games, "games", 1, "number of games to host, one after the other")
+	flags.BoolVar(&opts.quiet, "quiet", false, "don't narrate players joining and each action")
+	flags.IntVar(&opts.players, "players", 2, "number of players [2-6]")
+	flags.Var(&seats, "seat", "seat setup as n=remote, n=random, n=mcts:strength or n=engine:command (repeatable), seats are remote unless given")
+	flags.DurationVar(&opts.engineTime, "engine-time", time.Second, "how long external engines get for each move")
+	flags.DurationVar(&opts.moveTime, "move-time", 0, "how long every seat, remote players included, gets for each move (0 = no limit)")
+	fallback := flags.String("fallback", "first", "what to play for a seat that runs out of time, fails or disconnects: first, random or heuristic")
+	rulesPath := flags.String("rules", "", "path to a json file of rule variants")
+	seed := flags.Int64("seed", 0, "seed for the random number generator (0 = random)")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+
+	if opts.players < 2 || opts.players > acquire.MAX_PLAYERS {
+		return opts, fmt.Errorf("--players must be within 2-%d, was %d", acquire.MAX_PLAYERS, opts.players)
+	}
+
+	if opts.games < 1 {
+		return opts, errors.New("--games must be at least 1")
+	}
+
+	if opts.engineTime < time.Millisecond || opts.moveTime < 0 {
+		return opts, errors.New("--engine-time must be at least 1ms, and --move-time can't be negative")
+	}
+
+	opts.fallback, err = ai.ParseFallback(*fallback)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.rules = acquire.DefaultRules
+	if *rulesPath != "" {
+		opts.rules, err = cli.ReadRules(*rulesPath)
+		if err != nil {
+			return opts, err
+		}
+	}
+
+	opts.seats = make([]ai.Seat, opts.players)
+	for i := range opts.seats {
+		opts.seats[i] = ai.Seat{Type: ai.SeatRemote}
+	}
+
+	for _, seat := range seats {
+		numStr, agent, ok := strings.Cut(seat, "=")
+		num, err := strconv.Atoi(numStr)
+		if !ok || err != nil || num < 1 || num > opts.players {
+			return opts, fmt.Errorf("seat '%s' should be of the form n=type, with n within 1-%d", seat, opts.players)
+		}
+
+		opts.seats[num-1], err = ai.ParseSeat(agent)
+		if err != nil {
+			return opts, fmt.Errorf("seat %d: %w", num, err)
+		}
+		if opts.seats[num-1].Type == ai.SeatHuman {
+			return opts, fmt.Errorf("seat %d: human players join from elsewhere, seat them as remote", num)
+		}
+	}
+
+	if *seed != 0 {
+		rand.Seed(*seed)
+	}
+
+	return opts, nil
+}
+
+// serveHTTP
+// serves the http api and browser ui until the listener is closed
+func (opts options) serveHTTP(out io.Writer, listen func(network, address string) (net.Listener, error)) error {
+	log := out
+	if opts.quiet {
+		log = nil
+	}
+
+	listener, err := listen("tcp", opts.http)
+	if err != nil {
+		return err
+	}
+
+	api := server.NewAPI(log)
+	defer api.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/games", api)
+	mux.Handle("/games/", api)
+	mux.Handle("/", server.UI())
+
+	fmt.Fprintf(out, "Serving the http api on %s, open http://%s/ in a browser to play\n", listener.Addr(), listener.Addr())
+
+	err = http.Serve(listener, mux)
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// newTable
+// a table seated as the options say, which narrates to out unless it's quiet
+func (opts options) newTable(out io.Writer) (*server.Table, error) {
+	log := out
+	if opts.quiet {
+		log = nil
+	}
+
+	agents := make([]ai.Agent, len(opts.seats))
+	for i, seat := range opts.seats {
+		agents[i] = seat.NewAgent(opts.engineTime, nil)
+	}
+
+	table := server.NewTable(opts.rules, agents, log)
+	table.LimitMoves(opts.moveTime, opts.fallback)
+
+	return table, nil
+}
+
+func (opts options) numRemote() int {
+	n := 0
+	for _, seat := range opts.seats {
+		if seat.Type == ai.SeatRemote {
+			n++
+		}
+	}
+	return n
+}
+
+func printResult(out io.Writer, game *acquire.Game) {
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "End Reason: "+game.EndReason)
+
+	for _, player := range game.PlayerSlice() {
+		fmt.Fprintf(out, "%s: $%d\n", player.Description(), player.NetWorth(game))
+	}
+
+	winners := make([]string, 0)
+	for _, id := range game.Winners() {
+		winners = append(winners, game.GetPlayerById(int(id)).Name())
+	}
+	fmt.Fprintf(out, "Winner(s): %s\n\n", strings.Join(winners, ", "))
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"acquire/internal/server"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listen := func(string, string) (net.Listener, error) {
+		return listener, nil
+	}
+
+	out := &bytes.Buffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- run([]string{"--players", "3", "--seat", "2=random", "--seat", "3=mcts:20", "--seed", "1"}, out, listen)
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	encoder := json.NewEncoder(conn)
+	encoder.Encode(server.Message{Type: "join", Name: "Alice"})
+
+	// play the first action every turn until the game is over
+	var last server.Message
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		json.Unmarshal(scanner.Bytes(), &last)
+		if last.Type == "actions" {
+			encoder.Encode(server.Message{Type: "action", Action: last.Actions[0]})
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if last.Type != "gameover" {
+		t.Fatalf("the game should have been played to the end, the last message was %+v", last)
+	}
+
+	for _, expected := range []string{"waiting for 1 remote players", "Alice joined seat 1", "Alice (Remote): $", "Player 3 (MCTS-20): $", "Winner(s): "} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("the output should contain %q:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestServerHTTP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listen := func(string, string) (net.Listener, error) {
+		return listener, nil
+	}
+
+	out := &bytes.Buffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- run([]string{"--http", "127.0.0.1:0", "--seed", "1"}, out, listen)
+	}()
+
+	url := "http://" + listener.Addr().String() + "/games"
+	resp, err := http.Post(url, "application/json", strings.NewReader(`{"seats": [{"agent": "random"}, {"agent": "random"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected the game to be created, got %s", resp.Status)
+	}
+
+	// wait for the game to be played out
+	for after, over := 0, false; !over; {
+		resp, err := http.Get(fmt.Sprintf("%s/1/events?after=%d", url, after))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		polled := struct {
+			Events []server.Message `json:"events"`
+			Next   int              `json:"next"`
+		}{}
+		json.NewDecoder(resp.Body).Decode(&polled)
+		resp.Body.Close()
+
+		after = polled.Next
+		for _, event := range polled.Events {
+			over = over || event.Type == "gameover"
+		}
+	}
+
+	// the browser ui is served alongside the api
+	resp, err = http.Get("http://" + listener.Addr().String() + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("expected the browser ui to be served, got %s (%s)", resp.Status, resp.Header.Get("Content-Type"))
+	}
+
+	listener.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "game 1: over") {
+		t.Errorf("the game should have been narrated:\n%s", out.String())
+	}
+}
+
+func TestServerFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"--players", "7"},
+		{"--seat", "3=random"},
+		{"--seat", "1=robot"},
+		{"--seat", "1=remote:5"},
+		{"--seat", "1=human"},
+		{"--fallback", "resign"},
+		{"--games", "0"},
+	} {
+		if _, err := parseOptions(args); err == nil {
+			t.Errorf("expected %v to be rejected", args)
+		}
+	}
+}
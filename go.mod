@@ -4,4 +4,7 @@ go 1.20
 
 require (
 	git.sr.ht/~bonbon/gmcts v1.2.1
+	golang.org/x/term v0.21.0
 )
+
+require golang.org/x/sys v0.21.0 // indirect
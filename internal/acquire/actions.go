@@ -41,38 +41,55 @@ type IAction interface {
 	String(game *Game) string
 }
 
+// ApplyAction
+// returns a copy of the game with the action applied, the game itself is left as it was.
+// gmcts keeps every state it's given, so each one is a new game on the heap: this allocates (and copies) a
+// whole Game every time, which is all it allocates. Apply is the part which doesn't allocate, for callers
+// which can keep the copy themselves
 func (game *Game) ApplyAction(gmctsAction gmcts.Action) (gmcts.Game, error) {
 
-	gameStruct := *game
-	clone := gameStruct
-
 	action, ok := gmctsAction.(IAction)
 	if !ok {
 		panic("action type was not convertable to IAction")
 	}
 
+	clone := *game
+	clone.Apply(action)
+
+	return &clone, nil
+}
+
+// Apply
+// applies the action to the game in place, without allocating.
+// the game is a flat value (see Game), so copying it first (clone := *game) is all it takes to keep the original
+func (game *Game) Apply(action IAction) {
+
 	switch action.Type() {
 	case ActionType_PlaceTile:
-		clone.applyPlaceTileAction(util.AsType[Action_PlaceTile](action))
+		game.applyPlaceTileAction(util.AsType[Action_PlaceTile](action))
 	case ActionType_PickHotelToFound:
-		clone.applyPickHotelToFoundAction(util.AsType[Action_PickHotelToFound](action))
+		game.applyPickHotelToFoundAction(util.AsType[Action_PickHotelToFound](action))
 		break
 	case ActionType_PickHotelToMerge:
-		clone.applyPickHotelToMergeAction(util.AsType[Action_PickHotelToMerge](action))
+		game.applyPickHotelToMergeAction(util.AsType[Action_PickHotelToMerge](action))
 		break
 	case ActionType_Merge:
-		clone.applyMergeHotel(util.AsType[Action_Merge](action))
+		game.applyMergeHotel(util.AsType[Action_Merge](action))
 		break
 	case ActionType_PurchaseStock:
-		clone.applyPurchaseStockAction(util.AsType[Action_PurchaseStock](action))
+		game.applyPurchaseStockAction(util.AsType[Action_PurchaseStock](action))
 		break
 	default:
 		panic(fmt.Sprintf("action %d is not handled", action))
 	}
 
-	clone.Computed = NewComputed(&clone)
+	game.Computed.refresh(game)
 
-	return &clone, nil
+	if debug {
+		if err := game.CheckInvariants(); err != nil {
+			panic(fmt.Sprintf("after %s: %s", action.Type().String(), err))
+		}
+	}
 }
 
 func (game *Game) GetActions() []gmcts.Action {
@@ -11,7 +11,7 @@ type Action_PickHotelToFound struct {
 }
 
 func (a Action_PickHotelToFound) String(game *Game) string {
-	return fmt.Sprintf("Player %s chooses to found %s.", game.CurrentPlayer().Name(), a.Hotel.String())
+	return fmt.Sprintf("%s chooses to found %s.", game.CurrentPlayer().Name(), a.Hotel.String())
 }
 
 func (a Action_PickHotelToFound) Type() ActionType {
@@ -19,7 +19,7 @@ func (a Action_PickHotelToFound) Type() ActionType {
 }
 
 func (game *Game) getFoundHotelActions() []gmcts.Action {
-	return util.Map(game.Computed.AvailableChains, func(val Hotel) gmcts.Action {
+	return util.Map(game.Computed.AvailableChains(), func(val Hotel) gmcts.Action {
 		return Action_PickHotelToFound{Hotel: val}
 	})
 }
@@ -28,8 +28,11 @@ func (game *Game) applyPickHotelToFoundAction(action Action_PickHotelToFound) {
 
 	tile := game.LastPlacedTile
 
-	newPlacedHotel := game.placeTileOnBoard(tile, action.Hotel)
-	propagateHotelChain(game, newPlacedHotel)
+	// the tile was placed as undefined, so founding converts it and its neighbors to the chain
+	propagateHotelChain(game, PlacedHotel{
+		Hotel: action.Hotel,
+		Tile:  tile,
+	})
 
 	remainingStock := game.Stocks[action.Hotel.Index()]
 	err := game.CurrentPlayer().takeStockFromBank(game, action.Hotel, util.Min(1, remainingStock))
@@ -2,7 +2,6 @@ package acquire
 
 import (
 	"acquire/internal/util"
-	"errors"
 	"fmt"
 	"git.sr.ht/~bonbon/gmcts"
 	"strings"
@@ -55,7 +54,7 @@ func (a Action_Merge) String(game *Game) string {
 	})
 	mergedHotels := strings.Join(hotelNames, " and ")
 
-	return fmt.Sprintf("Player %s chooses to merge %s into %s via %s",
+	return fmt.Sprintf("%s chooses to merge %s into %s via %s",
 		game.ActivePlayer().Name(),
 		mergedHotels,
 		game.MergerState.AcquiringHotel.String(),
@@ -82,9 +81,9 @@ func (game *Game) getMergeHotelActions() []gmcts.Action {
 	})
 
 	activePlayer := game.ActivePlayer()
-	mergedHotel, err := game.getNextChainToMerge()
-	if err != nil {
-		panic(err)
+	mergedHotel, ok := game.getNextChainToMerge()
+	if !ok {
+		panic("merging, but there is no chain left to merge")
 	}
 
 	acquiringHotel := game.MergerState.AcquiringHotel
@@ -145,44 +144,49 @@ func (game *Game) getMergeHotelActions() []gmcts.Action {
 	return mergeActions
 }
 
-func (game *Game) getNextChainToMerge() (Hotel, error) {
+// getNextChainToMerge
+// the chain currently being merged, false if there aren't any left to merge
+func (game *Game) getNextChainToMerge() (Hotel, bool) {
 	mergerState := game.MergerState
 	for idx, playersRemaining := range mergerState.ChainsToMerge {
 		if playersRemaining > 0 {
-			return ChainFromIdx(idx), nil
+			return ChainFromIdx(idx), true
 		}
 	}
-	return NoHotel, errors.New("no valid hotel found")
+	return NoHotel, false
+}
+
+// nextMergingPlayer
+// moves the merger on to the next player, or to the next chain once everyone has dealt with this one.
+// once every chain has been merged, the tile which caused the merger is finally placed
+func (game *Game) nextMergingPlayer(hotelToMerge Hotel) {
+	game.MergerState.MergingPlayerIdx += 1
+	game.MergerState.MergingPlayerIdx = game.MergerState.MergingPlayerIdx % game.numRealPlayers()
+	game.MergerState.ChainsToMerge[hotelToMerge.Index()] -= 1
+
+	// if there's no more chains to process, we're done merging
+	_, ok := game.getNextChainToMerge()
+	if !ok {
+		// finally convert the placed piece and propagate the chain
+		propagateHotelChain(game, PlacedHotel{
+			Hotel: game.MergerState.AcquiringHotel,
+			Tile:  game.LastPlacedTile,
+		})
+
+		game.NextActionType = ActionType_PurchaseStock
+	}
 }
 
 func (game *Game) applyMergeHotel(action Action_Merge) {
 
-	hotelToMerge, err := game.getNextChainToMerge()
-	if err != nil {
-		panic(err)
+	hotelToMerge, ok := game.getNextChainToMerge()
+	if !ok {
+		panic("merging, but there is no chain left to merge")
 	}
 
 	// references references references...
 	player := &game.Players[game.MergerState.MergingPlayerIdx]
 
-	goNext := func() {
-		game.MergerState.MergingPlayerIdx += 1
-		game.MergerState.MergingPlayerIdx = game.MergerState.MergingPlayerIdx % game.numRealPlayers()
-		game.MergerState.ChainsToMerge[hotelToMerge.Index()] -= 1
-
-		// err wil be set if there are no more chains to merge
-		_, err := game.getNextChainToMerge()
-
-		// if there's no more chains to process, we're done merging
-		if err != nil {
-			// finally place the piece and propagate the chain
-			newPlacedHotel := game.placeTileOnBoard(game.LastPlacedTile, game.MergerState.AcquiringHotel)
-			propagateHotelChain(game, newPlacedHotel)
-
-			game.NextActionType = ActionType_PurchaseStock
-		}
-	}
-
 	// pay them boys
 	game.payShareholderBonuses(hotelToMerge)
 
@@ -193,7 +197,7 @@ func (game *Game) applyMergeHotel(action Action_Merge) {
 		switch subAction.MergeType {
 
 		case Hold:
-			goNext()
+			game.nextMergingPlayer(hotelToMerge)
 			return
 
 		case Trade:
@@ -212,5 +216,5 @@ func (game *Game) applyMergeHotel(action Action_Merge) {
 		}
 	}
 
-	goNext()
+	game.nextMergingPlayer(hotelToMerge)
 }
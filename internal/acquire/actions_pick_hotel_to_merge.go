@@ -15,25 +15,31 @@ func (a Action_PickHotelToMerge) Type() ActionType {
 }
 
 func (a Action_PickHotelToMerge) String(game *Game) string {
-	return fmt.Sprintf("Player %s chooses %s as the acquiring chain.",
+	return fmt.Sprintf("%s chooses %s as the acquiring chain.",
 		game.CurrentPlayer().Name(),
 		a.Hotel.String(),
 	)
 }
 
+// getPickHotelToMergeActions
+// the player picks between the chains in the merger which are tied for largest
 func (game *Game) getPickHotelToMergeActions() []gmcts.Action {
-	return util.Map(game.Computed.LargestChains, func(val Hotel) gmcts.Action {
+	largestChains, _ := game.getLargestChainsOf(game.chainsAround(game.LastPlacedTile))
+
+	return util.Map(largestChains.slice(), func(val Hotel) gmcts.Action {
 		return Action_PickHotelToMerge{Hotel: val}
 	})
 }
 
 func (game *Game) applyPickHotelToMergeAction(action Action_PickHotelToMerge) {
-	pos := game.LastPlacedTile.Pos()
-	neighboringHotels := getNeighbors(game, pos)
-	chainsInNeighbors := getChainsInNeighbors(neighboringHotels)
-	acquiredChains := util.Filter(chainsInNeighbors, func(val Hotel) bool {
-		return val != action.Hotel
-	})
+	chainsInNeighbors := game.chainsAround(game.LastPlacedTile)
+
+	var acquiredChains hotelSet
+	for _, h := range chainsInNeighbors.slice() {
+		if h != action.Hotel {
+			acquiredChains.add(h)
+		}
+	}
 	largestAcquiredChains, _ := game.getLargestChainsOf(acquiredChains)
 
 	// remove the acquiring hotel chain from the list to merge (by setting it to zero)
@@ -43,9 +49,8 @@ func (game *Game) applyPickHotelToMergeAction(action Action_PickHotelToMerge) {
 
 	// prepare the 'chains to merge' array
 	for _, h := range HotelChainList {
-		// ok = this hotel is in the 'largest chains' slice, but isn't the largest chain
-		_, ok := util.IndexOf(largestAcquiredChains, h)
-		if ok {
+		// this hotel is in the 'largest chains' set, but isn't the largest chain
+		if largestAcquiredChains.contains(h) {
 			game.MergerState.ChainsToMerge[h.Index()] = game.numRealPlayers()
 			game.MergerState.MergedChains[mergedChainCounter] = h
 			mergedChainCounter++
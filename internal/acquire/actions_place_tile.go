@@ -16,14 +16,14 @@ func (a Action_PlaceTile) Type() ActionType {
 }
 
 func (a Action_PlaceTile) String(game *Game) string {
-	return fmt.Sprintf("Player %s places tile %s.",
+	return fmt.Sprintf("%s places tile %s.",
 		game.CurrentPlayer().Name(),
 		a.Tile.String(),
 	)
 }
 
 func (game *Game) getPlaceTileActions() []gmcts.Action {
-	moves := game.Computed.LegalMoves
+	moves := game.Computed.LegalMoves()
 	var skip bool
 	if len(moves) < 1 {
 		skip = refreshOrSkip(game, game.CurrentPlayer(), 1)
@@ -46,10 +46,6 @@ func (game *Game) getPlaceTileActions() []gmcts.Action {
 
 func (game *Game) applyPlaceTileAction(action Action_PlaceTile) {
 
-	goNext := func() {
-		game.NextActionType = ActionType_PurchaseStock
-	}
-
 	// player wants to skip their turn
 	if action.Tile == NoTile {
 		game.SkippedTurnsInARow++
@@ -59,7 +55,7 @@ func (game *Game) applyPlaceTileAction(action Action_PlaceTile) {
 			return
 		}
 
-		goNext()
+		game.NextActionType = ActionType_PurchaseStock
 		return
 	}
 
@@ -73,71 +69,35 @@ func (game *Game) applyPlaceTileAction(action Action_PlaceTile) {
 		panic(err)
 	}
 
-	pos := tile.Pos()
-
-	neighboringHotels := getNeighbors(game, pos)
-
 	game.placeTileOnBoard(tile, UndefinedHotel)
 
 	// no neighbors - no effects, go to next player's turn
-	if !hasNeighboringHotel(neighboringHotels) {
-		goNext()
+	if !game.hasNeighboringHotel(tile) {
+		game.NextActionType = ActionType_PurchaseStock
 		return
 	}
 
 	// growing a chain - occurs when, of all neighbors, there is only one type of hotel
-	chainsInNeighbors := getChainsInNeighbors(neighboringHotels)
-	if len(chainsInNeighbors) == 1 {
-		hotel := chainsInNeighbors[0]
-		game.placeTileOnBoard(tile, hotel)
+	chainsInNeighbors := game.chainsAround(tile)
+	if chainsInNeighbors.len == 1 {
+		hotel := chainsInNeighbors.hotels[0]
 		propagateHotelChain(game, PlacedHotel{
 			Hotel: hotel,
 			Tile:  tile,
 		})
 
-		goNext()
+		game.NextActionType = ActionType_PurchaseStock
 		return
 	}
 
 	// merger - if there are more than two chains in the neighboring tiles, a merger must take place
-	if len(chainsInNeighbors) > 1 {
-
-		largestChains, _ := game.getLargestChainsOf(chainsInNeighbors)
-
-		game.MergerState = MergerState{
-			ChainsToMerge:    [7]int{},
-			MergingPlayerIdx: game.playerTurn(0),
-			AcquiringHotel:   largestChains[0], //select the largest chain by default
-		}
-
-		// more than one chain is tied for largest, player needs to decide which chain is acquired
-		if len(largestChains) > 1 {
-			game.NextActionType = ActionType_PickHotelToMerge
-			return
-		}
-
-		mergedChainCounter := 0
-		// prepare the 'chains to merge' array
-		for _, h := range chainsInNeighbors {
-			// ok = this hotel is in the largest_chains slice
-			_, ok := util.IndexOf(largestChains, h)
-			if !ok {
-				game.MergerState.ChainsToMerge[h.Index()] = game.numRealPlayers()
-				game.MergerState.MergedChains[mergedChainCounter] = h
-				mergedChainCounter++
-			}
-		}
-
-		// otherwise...
-
-		game.NextActionType = ActionType_Merge
-
+	if chainsInNeighbors.len > 1 {
+		game.startMerger(chainsInNeighbors)
 		return
 	}
 
 	// found a new chain - occurs when a tile has one or more neighbors which are all still undefined
-	undefinedNeighbors := getUndefinedNeighbors(neighboringHotels)
-	if len(undefinedNeighbors) > 0 {
+	if game.countUndefinedNeighbors(tile) > 0 {
 		game.NextActionType = ActionType_PickHotelToFound
 		game.FoundingHotel = NoHotel
 		return
@@ -146,51 +106,38 @@ func (game *Game) applyPlaceTileAction(action Action_PlaceTile) {
 	panic("unexpectedly got here")
 }
 
-// getChainNeighbors
-// returns a slice of the hotels around a position which are not NoHotel nor UndefinedHotel
-func getChainNeighbors(neighbors []PlacedHotel) []PlacedHotel {
-	chainNeighbors := make([]PlacedHotel, 0)
+// startMerger
+// sets up the merger of the chains around the last placed tile. when the largest chains are tied,
+// the player picks which one acquires the others first
+func (game *Game) startMerger(chainsInNeighbors hotelSet) {
+	largestChains, _ := game.getLargestChainsOf(chainsInNeighbors)
 
-	for _, d := range util.Directions {
-		if neighbors[d].Hotel != NoHotel && neighbors[d].Hotel != UndefinedHotel {
-			chainNeighbors = append(chainNeighbors, neighbors[d])
-		}
+	game.MergerState = MergerState{
+		ChainsToMerge:    [7]int{},
+		MergingPlayerIdx: game.playerTurn(0),
+		AcquiringHotel:   largestChains.hotels[0], //select the largest chain by default
 	}
 
-	return chainNeighbors
-}
-
-// getChainsInNeighbors
-// returns a slice of unique hotel chains which are in the neighbors slice
-func getChainsInNeighbors(neighbors []PlacedHotel) []Hotel {
-	chainNeighbors := getChainNeighbors(neighbors)
-	hotels := util.Map(chainNeighbors, func(val PlacedHotel) Hotel {
-		return val.Hotel
-	})
-	return util.UniqueElements[Hotel](hotels)
-}
-
-// hasNeighboringHotel
-// returns true if all the hotels in the slice are 'NoHotel'
-func hasNeighboringHotel(neighbors []PlacedHotel) bool {
-	for _, d := range util.Directions {
-		if neighbors[d].Hotel != NoHotel {
-			return true
-		}
+	// more than one chain is tied for largest, player needs to decide which chain is acquired
+	if largestChains.len > 1 {
+		game.NextActionType = ActionType_PickHotelToMerge
+		return
 	}
-	return false
-}
-
-func getUndefinedNeighbors(neighbors []PlacedHotel) []PlacedHotel {
-	undefinedNeighbors := make([]PlacedHotel, 0)
 
-	for _, d := range util.Directions {
-		if neighbors[d].Hotel == UndefinedHotel {
-			undefinedNeighbors = append(undefinedNeighbors, neighbors[d])
+	mergedChainCounter := 0
+	// prepare the 'chains to merge' array
+	for _, h := range chainsInNeighbors.slice() {
+		// this hotel isn't the largest chain, so it gets merged
+		if !largestChains.contains(h) {
+			game.MergerState.ChainsToMerge[h.Index()] = game.numRealPlayers()
+			game.MergerState.MergedChains[mergedChainCounter] = h
+			mergedChainCounter++
 		}
 	}
 
-	return undefinedNeighbors
+	// otherwise...
+
+	game.NextActionType = ActionType_Merge
 }
 
 // refreshOrSkip
@@ -199,8 +146,11 @@ func getUndefinedNeighbors(neighbors []PlacedHotel) []PlacedHotel {
 // (as indicated by true in the returned bool)
 func refreshOrSkip(game *Game, p *Player, n int) bool {
 	for i := 0; i < n; i++ {
-		if len(game.Computed.LegalMoves) < 1 {
+		if len(game.Computed.LegalMoves()) < 1 {
 			p.refreshTiles(game)
+			// the new hand has its own legal moves, which have to be up to date for the next check (and whoever
+			// looks at the game next), since there's no action being applied to bring them up to date
+			game.Computed.refresh(game)
 		} else {
 			return false
 		}
@@ -35,7 +35,7 @@ func (a Action_PurchaseStock) String(game *Game) string {
 		purchaseStr = "nothing"
 	}
 
-	return fmt.Sprintf("Player %s buys %s.",
+	return fmt.Sprintf("%s buys %s.",
 		game.CurrentPlayer().Name(),
 		purchaseStr,
 	)
@@ -77,7 +77,10 @@ func generateCombinations(activeHotels []Hotel) [][]Hotel {
 
 func (game *Game) getPurchaseStockActions() []gmcts.Action {
 
-	options := append(game.Computed.ActiveChains, NoHotel)
+	// copied so the computed slice isn't appended to
+	options := make([]Hotel, 0, NUM_CHAINS+1)
+	options = append(options, game.Computed.ActiveChains()...)
+	options = append(options, NoHotel)
 	combinations := generateCombinations(options)
 
 	actions := make([]gmcts.Action, 0, 32)
@@ -195,6 +198,7 @@ func (game *Game) applyPurchaseStockAction(action Action_PurchaseStock) {
 
 	game.NextActionType = ActionType_PlaceTile
 	game.Turn++
+	game.markLegalMovesDirty()
 }
 
 func (game *Game) Abort(reason string) {
@@ -202,9 +206,10 @@ func (game *Game) Abort(reason string) {
 	os.Exit(1)
 }
 
-func (game *Game) end(_ string) {
+func (game *Game) end(reason string) {
 
 	game.IsOver = true
+	game.EndReason = reason
 
 	// payout shareholder bonuses
 	for _, hotel := range HotelChainList {
@@ -243,20 +248,28 @@ func (game *Game) hasRemainingTiles() bool {
 }
 
 // CanEnd
-// returns true if it's possible for a player to 'declare' the game over
+// returns true if it's possible for a player to 'declare' the game over, which needs at least one chain on the
+// board, and either one of them to have reached the end size or all of them to be safe (see Rules).
+// declaring isn't wired up yet (nothing sets WillEnd), so this only decides the End flag of the tile placing
+// actions and what's reported about finished games, it never ends one
 func (game *Game) CanEnd() (string, bool) {
 
-	// if there are any chains larger than 40, the game can end
-	for _, size := range game.Computed.LargestChains {
-		if size > 40 {
-			return "there is a chain with length 41", true
+	// there has to be at least one chain on the board to end the game
+	if len(game.Computed.ActiveChains()) < 1 {
+		return "", false
+	}
+
+	// if there are any chains of the ending size, the game can end
+	for _, hotel := range game.Computed.ActiveChains() {
+		if game.ChainSize[hotel.Index()] >= game.Rules.EndChainSize {
+			return fmt.Sprintf("there is a chain with length %d", game.Rules.EndChainSize), true
 		}
 	}
 
 	// get any unsafe active chains
 	hasUnsafe := func() bool {
-		for _, size := range game.Computed.ActiveChains {
-			if size <= 10 {
+		for _, hotel := range game.Computed.ActiveChains() {
+			if game.ChainSize[hotel.Index()] < game.Rules.SafeChainSize {
 				return true
 			}
 		}
@@ -0,0 +1,136 @@
+package acquire
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// recordRandomGames
+// plays random games, returning every state along with the action that was taken from it
+func recordRandomGames(numGames int) ([]Game, []IAction) {
+	states := make([]Game, 0)
+	actions := make([]IAction, 0)
+
+	for g := 0; g < numGames; g++ {
+		game := NewGame()
+
+		for !game.IsTerminal() {
+			gameActions := game.GetActions()
+			if game.IsTerminal() {
+				break
+			}
+
+			action := gameActions[rand.Intn(len(gameActions))].(IAction)
+			states = append(states, *game)
+			actions = append(actions, action)
+
+			newGame, _ := game.ApplyAction(action)
+			game = newGame.(*Game)
+		}
+	}
+
+	return states, actions
+}
+
+func TestApplyDoesNotAllocate(t *testing.T) {
+	if debug {
+		t.Skip("checking the invariants after every action allocates")
+	}
+
+	rand.Seed(1)
+	states, actions := recordRandomGames(20)
+
+	var clone Game
+	for i := range states {
+		allocs := testing.AllocsPerRun(1, func() {
+			clone = states[i]
+			clone.Apply(actions[i])
+		})
+
+		if allocs > 0 {
+			t.Fatalf("applying %s (%s) allocated %.0f times",
+				actions[i].Type().String(),
+				actions[i].String(&states[i]),
+				allocs,
+			)
+		}
+	}
+}
+
+// TestGameIsFlat
+// copying a game shouldn't share anything which can change, so the only references allowed are strings
+func TestGameIsFlat(t *testing.T) {
+	var check func(typ reflect.Type, path string)
+	check = func(typ reflect.Type, path string) {
+		switch typ.Kind() {
+		case reflect.Struct:
+			for i := 0; i < typ.NumField(); i++ {
+				check(typ.Field(i).Type, path+"."+typ.Field(i).Name)
+			}
+		case reflect.Array:
+			check(typ.Elem(), path+"[]")
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			t.Errorf("%s is a %s, which copies of the game would share", path, typ.Kind())
+		}
+	}
+
+	check(reflect.TypeOf(Game{}), "Game")
+}
+
+// BenchmarkApply
+// copying a game and applying an action to the copy, which shouldn't allocate
+func BenchmarkApply(b *testing.B) {
+	rand.Seed(1)
+	states, actions := recordRandomGames(20)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var clone Game
+	for i := 0; i < b.N; i++ {
+		n := i % len(states)
+		clone = states[n]
+		clone.Apply(actions[n])
+	}
+}
+
+// TestApplyActionAllocatesTheCopy
+// ApplyAction allocates the game it returns, and nothing else
+func TestApplyActionAllocatesTheCopy(t *testing.T) {
+	if debug {
+		t.Skip("checking the invariants after every action allocates")
+	}
+
+	rand.Seed(1)
+	states, actions := recordRandomGames(5)
+
+	for i := range states {
+		allocs := testing.AllocsPerRun(1, func() {
+			_, _ = states[i].ApplyAction(actions[i])
+		})
+
+		if allocs != 1 {
+			t.Fatalf("applying %s (%s) allocated %.0f times, expected only the copy",
+				actions[i].Type().String(),
+				actions[i].String(&states[i]),
+				allocs,
+			)
+		}
+	}
+}
+
+// BenchmarkApplyAction
+// the same through gmcts' ApplyAction, which allocates the copy it returns (one allocation per action)
+func BenchmarkApplyAction(b *testing.B) {
+	rand.Seed(1)
+	states, actions := recordRandomGames(20)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		n := i % len(states)
+		_, _ = states[n].ApplyAction(actions[n])
+	}
+}
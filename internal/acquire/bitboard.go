@@ -0,0 +1,226 @@
+package acquire
+
+import "math/bits"
+
+// Bitboard
+// one bit per board position, indexed the same as Board (y * BOARD_MAX_X + x).
+// the 108 positions don't fit in a uint64, so the first 64 are in Lo and the rest in Hi
+type Bitboard struct {
+	Lo uint64
+	Hi uint64
+}
+
+// fullBoard
+// every position on the board, used to mask off the unused bits of Hi
+var fullBoard = Bitboard{
+	Lo: ^uint64(0),
+	Hi: (uint64(1) << (BOARD_MAX_X*BOARD_MAX_Y - 64)) - 1,
+}
+
+// the first and last columns, a position in these mustn't wrap around into the next or previous row when shifted
+var firstColumn, lastColumn = func() (Bitboard, Bitboard) {
+	var first, last Bitboard
+	for y := 0; y < BOARD_MAX_Y; y++ {
+		first = first.With(index(0, y))
+		last = last.With(index(BOARD_MAX_X-1, y))
+	}
+	return first, last
+}()
+
+// TileBitboard
+// a bitboard with only the tile's position set
+func TileBitboard(tile Tile) Bitboard {
+	if tile == NoTile {
+		return Bitboard{}
+	}
+
+	return Bitboard{}.With(tile.Index())
+}
+
+// With
+// the bitboard with the position at idx set
+func (b Bitboard) With(idx int) Bitboard {
+	if idx < 64 {
+		b.Lo |= 1 << idx
+	} else {
+		b.Hi |= 1 << (idx - 64)
+	}
+	return b
+}
+
+// Without
+// the bitboard with the position at idx cleared
+func (b Bitboard) Without(idx int) Bitboard {
+	if idx < 64 {
+		b.Lo &^= 1 << idx
+	} else {
+		b.Hi &^= 1 << (idx - 64)
+	}
+	return b
+}
+
+func (b Bitboard) Has(idx int) bool {
+	if idx < 64 {
+		return b.Lo&(1<<idx) != 0
+	}
+	return b.Hi&(1<<(idx-64)) != 0
+}
+
+func (b Bitboard) HasTile(tile Tile) bool {
+	return tile != NoTile && b.Has(tile.Index())
+}
+
+func (b Bitboard) And(o Bitboard) Bitboard {
+	return Bitboard{Lo: b.Lo & o.Lo, Hi: b.Hi & o.Hi}
+}
+
+func (b Bitboard) Or(o Bitboard) Bitboard {
+	return Bitboard{Lo: b.Lo | o.Lo, Hi: b.Hi | o.Hi}
+}
+
+// AndNot
+// the positions in b which aren't in o
+func (b Bitboard) AndNot(o Bitboard) Bitboard {
+	return Bitboard{Lo: b.Lo &^ o.Lo, Hi: b.Hi &^ o.Hi}
+}
+
+// Intersects
+// true if any position is set in both bitboards
+func (b Bitboard) Intersects(o Bitboard) bool {
+	return b.Lo&o.Lo != 0 || b.Hi&o.Hi != 0
+}
+
+func (b Bitboard) IsEmpty() bool {
+	return b.Lo == 0 && b.Hi == 0
+}
+
+// Count
+// the number of positions set
+func (b Bitboard) Count() int {
+	return bits.OnesCount64(b.Lo) + bits.OnesCount64(b.Hi)
+}
+
+// Tiles
+// the tiles at each set position, in board order
+func (b Bitboard) Tiles() []Tile {
+	tiles := make([]Tile, 0, b.Count())
+	for lo := b.Lo; lo != 0; lo &= lo - 1 {
+		tiles = append(tiles, TileFromBoardIdx(bits.TrailingZeros64(lo)))
+	}
+	for hi := b.Hi; hi != 0; hi &= hi - 1 {
+		tiles = append(tiles, TileFromBoardIdx(64+bits.TrailingZeros64(hi)))
+	}
+	return tiles
+}
+
+// shiftUp
+// moves every position n indexes higher, dropping anything shifted off the board
+func (b Bitboard) shiftUp(n uint) Bitboard {
+	return Bitboard{
+		Lo: b.Lo << n,
+		Hi: b.Hi<<n | b.Lo>>(64-n),
+	}.And(fullBoard)
+}
+
+// shiftDown
+// moves every position n indexes lower
+func (b Bitboard) shiftDown(n uint) Bitboard {
+	return Bitboard{
+		Lo: b.Lo>>n | b.Hi<<(64-n),
+		Hi: b.Hi >> n,
+	}
+}
+
+// Neighbors
+// every position orthogonally next to a set position, which isn't set itself
+func (b Bitboard) Neighbors() Bitboard {
+	east := b.AndNot(lastColumn).shiftUp(1)
+	west := b.AndNot(firstColumn).shiftDown(1)
+	south := b.shiftUp(BOARD_MAX_X)
+	north := b.shiftDown(BOARD_MAX_X)
+
+	return east.Or(west).Or(south).Or(north).AndNot(b)
+}
+
+// Occupied
+// every position with a tile on it
+func (game *Game) Occupied() Bitboard {
+	return game.occupied
+}
+
+// ChainBitboard
+// every position belonging to the hotel, for UndefinedHotel this is the tiles not in any chain
+func (game *Game) ChainBitboard(hotel Hotel) Bitboard {
+	switch hotel {
+	case NoHotel:
+		return fullBoard.AndNot(game.occupied)
+	case UndefinedHotel:
+		return game.unincorporated()
+	default:
+		return game.chainBoards[hotel.Index()]
+	}
+}
+
+// unincorporated
+// the tiles on the board which aren't part of a chain
+func (game *Game) unincorporated() Bitboard {
+	incorporated := Bitboard{}
+	for _, b := range game.chainBoards {
+		incorporated = incorporated.Or(b)
+	}
+	return game.occupied.AndNot(incorporated)
+}
+
+// LegalMovesBitboard
+// the positions of the current player's legal moves. these are worked out from their hand rather than taken
+// from Computed, which only has them up to date once it's been refreshed
+func (game *Game) LegalMovesBitboard() Bitboard {
+	b := Bitboard{}
+	for _, t := range game.CurrentPlayer().Tiles {
+		if legal, _ := game.Computed.isLegalToPlace(game, t); legal {
+			b = b.With(t.Index())
+		}
+	}
+	return b
+}
+
+// setBitboardHotel
+// moves the position at idx into the hotel's bitboard (and out of whichever it was in)
+func (game *Game) setBitboardHotel(idx int, oldHotel Hotel, hotel Hotel) {
+	game.occupied = game.occupied.With(idx)
+
+	if oldHotel != NoHotel && oldHotel != UndefinedHotel {
+		game.chainBoards[oldHotel.Index()] = game.chainBoards[oldHotel.Index()].Without(idx)
+	}
+
+	if hotel != NoHotel && hotel != UndefinedHotel {
+		game.chainBoards[hotel.Index()] = game.chainBoards[hotel.Index()].With(idx)
+	}
+}
+
+// chainsAround
+// the unique chains next to the tile, in HotelChainList order
+func (game *Game) chainsAround(tile Tile) hotelSet {
+	var chains hotelSet
+
+	neighbors := TileBitboard(tile).Neighbors()
+	for idx, b := range game.chainBoards {
+		if b.Intersects(neighbors) {
+			chains.add(ChainFromIdx(idx))
+		}
+	}
+
+	return chains
+}
+
+// hasNeighboringHotel
+// true if there's any tile next to the given one
+func (game *Game) hasNeighboringHotel(tile Tile) bool {
+	return TileBitboard(tile).Neighbors().Intersects(game.occupied)
+}
+
+// countUndefinedNeighbors
+// the number of neighbors which are placed but aren't part of a chain yet
+func (game *Game) countUndefinedNeighbors(tile Tile) int {
+	return TileBitboard(tile).Neighbors().And(game.unincorporated()).Count()
+}
@@ -0,0 +1,76 @@
+package acquire
+
+import (
+	"acquire/internal/util"
+	"math/rand"
+	"testing"
+)
+
+// TestBitboardNeighbors
+// compares the shifted neighbor masks against the neighbors of every position, including at the edges
+// and across the boundary between Lo and Hi
+func TestBitboardNeighbors(t *testing.T) {
+	for idx := 0; idx < BOARD_MAX_X*BOARD_MAX_Y; idx++ {
+		tile := TileFromBoardIdx(idx)
+
+		expected := Bitboard{}
+		for _, npt := range tile.Pos().OrthogonalNeighbours() {
+			if isInBounds(npt.X, npt.Y) {
+				expected = expected.With(index(npt.X, npt.Y))
+			}
+		}
+
+		if neighbors := TileBitboard(tile).Neighbors(); neighbors != expected {
+			t.Fatalf("the neighbors of %s are %v, should be %v", tile.String(), neighbors.Tiles(), expected.Tiles())
+		}
+	}
+}
+
+// TestBitboardsMatchBoard
+// plays random games, checking that the bitboards agree with the board
+func TestBitboardsMatchBoard(t *testing.T) {
+	rand.Seed(3)
+	states, _ := recordRandomGames(50)
+
+	for i := range states {
+		game := &states[i]
+
+		for idx, placed := range game.Board {
+			if game.Occupied().Has(idx) != (placed.Hotel != NoHotel) {
+				t.Fatalf("state %d: %s is occupied on the bitboard but has %s on the board",
+					i, TileFromBoardIdx(idx).String(), placed.Hotel.String())
+			}
+
+			for _, hotel := range append([]Hotel{UndefinedHotel}, HotelChainList...) {
+				if game.ChainBitboard(hotel).Has(idx) != (placed.Hotel == hotel) {
+					t.Fatalf("state %d: %s is on the %s bitboard but has %s on the board",
+						i, TileFromBoardIdx(idx).String(), hotel.String(), placed.Hotel.String())
+				}
+			}
+		}
+
+		for _, hotel := range HotelChainList {
+			if game.ChainBitboard(hotel).Count() != game.ChainSize[hotel.Index()] {
+				t.Fatalf("state %d: the %s bitboard has %d tiles, but the chain is size %d",
+					i, hotel.String(), game.ChainBitboard(hotel).Count(), game.ChainSize[hotel.Index()])
+			}
+		}
+	}
+}
+
+func TestBitboardTiles(t *testing.T) {
+	tiles := []Tile{Tile1A, TileFromBoardIdx(63), TileFromBoardIdx(64), TileFromBoardIdx(BOARD_MAX_X*BOARD_MAX_Y - 1)}
+
+	b := Bitboard{}
+	for _, tile := range tiles {
+		b = b.With(tile.Index())
+	}
+
+	if !equalSlices(b.Tiles(), tiles) {
+		t.Fatalf("the tiles were %v, should be %v", b.Tiles(), tiles)
+	}
+
+	if _, ok := util.IndexOf(b.Without(63).Tiles(), TileFromBoardIdx(63)); ok {
+		t.Fatal("removing a tile should take it off the bitboard")
+	}
+}
@@ -1,118 +1,168 @@
 package acquire
 
-import (
-	"sort"
-)
-
+// Computed
+// values derived from the rest of the game state, kept around since they are needed constantly.
+// rather than being recomputed after every action, these are kept up to date by the functions which
+// modify the state they're derived from (see modifyChainSize and markLegalMovesDirty).
+// everything is kept in fixed size arrays so that copying a game never has to allocate
 type Computed struct {
-	AvailableChains []Hotel
-	ActiveChains    []Hotel
-	LargestChains   []Hotel
+	availableChains    [NUM_CHAINS]Hotel
+	numAvailableChains int
+	activeChains       [NUM_CHAINS]Hotel
+	numActiveChains    int
+	largestChains      [NUM_CHAINS]Hotel
 
 	// the current player's legal moves
-	LegalMoves []Tile
+	legalMoves    [MAX_TILES_IN_HAND]Tile
+	numLegalMoves int
 
-	// mapping of player id to their total net worth (money + value of currently held stocks)
-	PlayerNetWorth map[int]int
+	// set when something changed which could change the legal moves, they're recomputed in refresh
+	legalMovesDirty bool
 }
 
-func NewComputed(game *Game) *Computed {
-	computed := &Computed{}
+// NewComputed
+// computes everything from scratch, this is needed whenever the game state was modified directly
+func NewComputed(game *Game) Computed {
+	var computed Computed
 
 	computed.computeChains(game)
 	computed.computeLegalMoves(game)
-	computed.computePlayerNetWorths(game)
 
 	return computed
 }
 
-func (c *Computed) computePlayerNetWorths(game *Game) {
-	c.PlayerNetWorth = make(map[int]int)
-	for _, p := range game.Players {
-		netWorth := p.Money
-		for _, chain := range c.ActiveChains {
-			netWorth += sharesCalc(chain, game.ChainSize[chain.Index()], p.Stocks[chain.Index()])
-		}
-		c.PlayerNetWorth[p.Id] = netWorth
-	}
+// AvailableChains
+// the chains which aren't on the board, in HotelChainList order
+func (c *Computed) AvailableChains() []Hotel {
+	return c.availableChains[:c.numAvailableChains]
 }
 
-func (c *Computed) computeChains(game *Game) {
-	availableChains := make([]Hotel, 0, NUM_CHAINS/2)
-	activeChains := make([]Hotel, 0, NUM_CHAINS/2)
-	largestChains := make([]Hotel, 0, len(game.ChainSize))
+// ActiveChains
+// the chains which are on the board, in HotelChainList order
+func (c *Computed) ActiveChains() []Hotel {
+	return c.activeChains[:c.numActiveChains]
+}
 
-	for idx, size := range game.ChainSize {
-		hotel := ChainFromIdx(idx)
-		if size == 0 {
-			availableChains = append(availableChains, hotel)
-		} else {
-			activeChains = append(activeChains, hotel)
-		}
+// LargestChains
+// every chain, from largest to smallest
+func (c *Computed) LargestChains() []Hotel {
+	return c.largestChains[:]
+}
+
+// LegalMoves
+// the tiles in the current player's hand which they are allowed to place
+func (c *Computed) LegalMoves() []Tile {
+	return c.legalMoves[:c.numLegalMoves]
+}
 
-		largestChains = append(largestChains, hotel)
+// refresh
+// brings anything which was marked out of date back up to date
+func (c *Computed) refresh(game *Game) {
+	if c.legalMovesDirty {
+		c.computeLegalMoves(game)
 	}
+}
 
-	// sorts hotels largest to smallest, by chain size
-	sort.Slice(largestChains, func(i, j int) bool {
-		return game.ChainSize[largestChains[i].Index()] > game.ChainSize[largestChains[j].Index()]
-	})
+// markLegalMovesDirty
+// call whenever the board, the hands, the turn or the chain sizes change
+func (game *Game) markLegalMovesDirty() {
+	game.Computed.legalMovesDirty = true
+}
 
-	c.AvailableChains = availableChains
-	c.ActiveChains = activeChains
-	c.LargestChains = largestChains
+// chainSizeChanged
+// updates the chain arrays after the size of a chain went from 'before' to its current size
+func (c *Computed) chainSizeChanged(game *Game, hotel Hotel, before int) {
+	after := game.ChainSize[hotel.Index()]
 
+	// the chain was founded or went defunct
+	if (before == 0) != (after == 0) {
+		c.computeActiveChains(game)
+	}
+
+	// the array is almost always still sorted, so an insertion sort barely does anything
+	c.sortLargestChains(game)
+
+	c.legalMovesDirty = true
 }
 
-// getLargestChainsOf
-// returns the hotel(s) with the largest size, and the size of the largest hotel(s)
-// the slice is in descending chain size order
-func (game *Game) getLargestChainsOf(hotels []Hotel) ([]Hotel, int) {
-	if len(hotels) < 1 {
-		return []Hotel{}, 0
+// isLargerChain
+// orders chains largest to smallest, ties go in HotelChainList order
+func isLargerChain(game *Game, a Hotel, b Hotel) bool {
+	sizeA := game.ChainSize[a.Index()]
+	sizeB := game.ChainSize[b.Index()]
+	if sizeA != sizeB {
+		return sizeA > sizeB
 	}
+	return a < b
+}
 
-	type HC struct {
-		hotel Hotel
-		count int
-	}
+func (c *Computed) computeChains(game *Game) {
+	c.computeActiveChains(game)
+
+	copy(c.largestChains[:], HotelChainList)
+	c.sortLargestChains(game)
+}
 
-	sizes := make([]HC, 0)
+func (c *Computed) computeActiveChains(game *Game) {
+	c.numAvailableChains = 0
+	c.numActiveChains = 0
 
-	for _, h := range hotels {
-		sizes = append(sizes, HC{
-			hotel: h,
-			count: game.ChainSize[h.Index()],
-		})
+	for idx, size := range game.ChainSize {
+		hotel := ChainFromIdx(idx)
+		if size == 0 {
+			c.availableChains[c.numAvailableChains] = hotel
+			c.numAvailableChains++
+		} else {
+			c.activeChains[c.numActiveChains] = hotel
+			c.numActiveChains++
+		}
 	}
+}
 
-	sort.Slice(sizes, func(i, j int) bool {
-		return sizes[i].count > sizes[j].count
-	})
+// sortLargestChains
+// sorts hotels largest to smallest, by chain size
+func (c *Computed) sortLargestChains(game *Game) {
+	for i := 1; i < len(c.largestChains); i++ {
+		for j := i; j > 0 && isLargerChain(game, c.largestChains[j], c.largestChains[j-1]); j-- {
+			c.largestChains[j], c.largestChains[j-1] = c.largestChains[j-1], c.largestChains[j]
+		}
+	}
+}
+
+// getLargestChainsOf
+// returns the hotel(s) with the largest size, and the size of the largest hotel(s)
+// tied hotels are in the same order as they were in the given set
+func (game *Game) getLargestChainsOf(hotels hotelSet) (hotelSet, int) {
+	var largestChains hotelSet
+	largestSize := 0
+
+	for _, h := range hotels.slice() {
+		size := game.ChainSize[h.Index()]
+		if size > largestSize {
+			largestSize = size
+			largestChains = hotelSet{}
+		}
 
-	// take all the hotels with the same length as the largest chain
-	largestChain := sizes[0]
-	largestChains := make([]Hotel, 0)
-	for _, hc := range sizes {
-		if hc.count == largestChain.count {
-			largestChains = append(largestChains, hc.hotel)
+		if size == largestSize {
+			largestChains.add(h)
 		}
 	}
 
-	return largestChains, sizes[0].count
+	return largestChains, largestSize
 }
 
 func (c *Computed) computeLegalMoves(game *Game) {
-	legalMoves := make([]Tile, 0, len(game.CurrentPlayer().Tiles))
+	c.numLegalMoves = 0
 
 	for _, t := range game.CurrentPlayer().Tiles {
 		legal, _ := c.isLegalToPlace(game, t)
 		if legal {
-			legalMoves = append(legalMoves, t)
+			c.legalMoves[c.numLegalMoves] = t
+			c.numLegalMoves++
 		}
 	}
 
-	c.LegalMoves = legalMoves
+	c.legalMovesDirty = false
 }
 
 func (c *Computed) isLegalToPlace(game *Game, tile Tile) (bool, string) {
@@ -121,17 +171,15 @@ func (c *Computed) isLegalToPlace(game *Game, tile Tile) (bool, string) {
 		return false, "no tile is not legal to place"
 	}
 
-	pos := tile.Pos()
-	neighboringHotels := getNeighbors(game, pos)
-	chainsInNeighbors := getChainsInNeighbors(neighboringHotels)
+	chainsInNeighbors := game.chainsAround(tile)
 
 	// this tile would start a merger if placed
-	if len(chainsInNeighbors) > 1 {
-		// if any two neighbors are size > 10, then the placement isn't legal
+	if chainsInNeighbors.len > 1 {
+		// if any two neighbors are safe, then the placement isn't legal
 		numSafe := 0
-		for _, hotel := range chainsInNeighbors {
+		for _, hotel := range chainsInNeighbors.slice() {
 			size := game.ChainSize[hotel.Index()]
-			if size > 11 {
+			if size >= game.Rules.SafeChainSize {
 				numSafe += 1
 			}
 
@@ -142,15 +190,14 @@ func (c *Computed) isLegalToPlace(game *Game, tile Tile) (bool, string) {
 	}
 
 	// this would grow a single chain if placed
-	if len(chainsInNeighbors) == 1 {
+	if chainsInNeighbors.len == 1 {
 		return true, ""
 	}
 
 	// this would found a new chain if placed
-	undefinedNeighbors := getUndefinedNeighbors(neighboringHotels)
-	if len(undefinedNeighbors) > 0 {
+	if game.countUndefinedNeighbors(tile) > 0 {
 		// if there are no available chains left to create, this move is invalid
-		if len(game.Computed.AvailableChains) == 0 {
+		if c.numAvailableChains == 0 {
 			return false, "there are no remaining hotels to found a chain with"
 		}
 	}
@@ -0,0 +1,120 @@
+package acquire
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// TestIncrementalComputed
+// plays random games, checking after every action that the incrementally updated computed values
+// are the same as computing them from scratch
+func TestIncrementalComputed(t *testing.T) {
+	numGames := 2000
+	if testing.Short() {
+		numGames = 100
+	}
+
+	rand.Seed(1)
+
+	for g := 0; g < numGames; g++ {
+		game := NewGame()
+
+		for !game.IsTerminal() {
+			actions := game.GetActions()
+
+			// getting the actions can refresh the player's hand, or end the game
+			full := NewComputed(game)
+			err := compareComputed(&game.Computed, &full)
+			if err != nil {
+				t.Fatalf("game %d, turn %d, getting actions: %s", g, game.Turn, err)
+			}
+
+			if game.IsTerminal() {
+				break
+			}
+
+			newGame, err := game.ApplyAction(actions[rand.Intn(len(actions))])
+			if err != nil {
+				t.Fatal(err)
+			}
+			game = newGame.(*Game)
+
+			full = NewComputed(game)
+			err = compareComputed(&game.Computed, &full)
+			if err != nil {
+				t.Fatalf("game %d, turn %d: %s", g, game.Turn, err)
+			}
+		}
+	}
+}
+
+// TestComputedAfterHandRefresh
+// a player with nothing they can play has their hand refreshed while getting the actions, and the legal moves
+// are those of the new hand straight away
+func TestComputedAfterHandRefresh(t *testing.T) {
+	rand.Seed(3)
+
+	// every tile in the hand would merge the two safe chains
+	game, err := ParsePosition(`
+		players: 2
+		player 1: tiles 1B 2B 3B 4B 5B 6B
+		a  W  W  W  W  W  W  W  W  W  W  W  W
+		b  □  □  □  □  □  □  □  □  □  □  □  □
+		c  T  T  T  T  T  T  T  T  T  T  T  T
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(game.Computed.LegalMoves()) != 0 {
+		t.Fatalf("the hand shouldn't have any legal moves, had %v", game.Computed.LegalMoves())
+	}
+
+	hand := game.CurrentPlayer().Tiles
+	game.GetActions()
+	if game.CurrentPlayer().Tiles == hand {
+		t.Fatal("the hand should have been refreshed")
+	}
+
+	full := NewComputed(game)
+	if !equalSlices(game.Computed.LegalMoves(), full.LegalMoves()) {
+		t.Errorf("legal moves %v after the refresh, should be %v", game.Computed.LegalMoves(), full.LegalMoves())
+	}
+}
+
+func compareComputed(incremental *Computed, full *Computed) error {
+	if !equalSlices(incremental.AvailableChains(), full.AvailableChains()) {
+		return fmt.Errorf("available chains %v, should be %v", incremental.AvailableChains(), full.AvailableChains())
+	}
+
+	if !equalSlices(incremental.ActiveChains(), full.ActiveChains()) {
+		return fmt.Errorf("active chains %v, should be %v", incremental.ActiveChains(), full.ActiveChains())
+	}
+
+	if !equalSlices(incremental.LargestChains(), full.LargestChains()) {
+		return fmt.Errorf("largest chains %v, should be %v", incremental.LargestChains(), full.LargestChains())
+	}
+
+	if !equalSlices(incremental.LegalMoves(), full.LegalMoves()) {
+		return fmt.Errorf("legal moves %v, should be %v", incremental.LegalMoves(), full.LegalMoves())
+	}
+
+	return nil
+}
+
+// equalSlices
+// nil and empty slices are considered equal, unlike with reflect.DeepEqual
+func equalSlices[T comparable](a []T, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
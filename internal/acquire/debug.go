@@ -0,0 +1,7 @@
+//go:build debug
+
+package acquire
+
+// debug
+// set by building with -tags debug, which checks the game's invariants after every action
+const debug = true
@@ -0,0 +1,5 @@
+//go:build !debug
+
+package acquire
+
+const debug = false
@@ -2,6 +2,7 @@ package acquire
 
 import (
 	"acquire/internal/util"
+	"fmt"
 	"git.sr.ht/~bonbon/gmcts"
 	"sort"
 )
@@ -12,6 +13,7 @@ const BOARD_MAX_Y = 9
 const MAX_PLAYERS = 6
 const MAX_TILES_IN_HAND = 6
 const NUM_CHAINS = 7
+const TOTAL_STOCKS = 25
 
 type PlacedHotel struct {
 	Hotel Hotel
@@ -34,6 +36,11 @@ type MergerState struct {
 	MergedChains [3]Hotel
 }
 
+// Game
+// the whole state of a game as a value: copying it copies everything, and applying an action to the copy in
+// place never allocates (see Apply, ApplyAction still allocates the copy it returns). it isn't entirely free
+// of heap pointers, the strings naming the players and the reason the game ended are shared between copies,
+// which is safe since strings are never changed in place
 type Game struct {
 	// indicates that this game is part of a simulation
 	// useful for debugging
@@ -46,11 +53,24 @@ type Game struct {
 	IsOver             bool
 	WillEnd            bool
 
+	// why the game ended, set once IsOver is
+	EndReason string
+
 	LastPlacedTile Tile
 
 	Board [BOARD_MAX_X * BOARD_MAX_Y]PlacedHotel
 	Tiles [BOARD_MAX_X * BOARD_MAX_Y]Tile
 
+	// which tiles on the board are connected, kept in sync with Board
+	tileSets tileSets
+
+	// the board, stocks and money part of Hash, kept up to date as they change
+	hash uint64
+
+	// the same board as bitboards, for fast neighbor queries. kept in sync with Board
+	occupied    Bitboard
+	chainBoards [NUM_CHAINS]Bitboard
+
 	// index by hotel
 	ChainSize [NUM_CHAINS]int
 	Stocks    [NUM_CHAINS]int
@@ -61,26 +81,35 @@ type Game struct {
 
 	MergerState MergerState
 
-	Computed *Computed
+	Rules Rules
+
+	Computed Computed
 }
 
 func NewGame() *Game {
+	return NewGameWithRules(DefaultRules)
+}
+
+// NewGameWithRules
+// same as NewGame, but played with a variant of the rules
+func NewGameWithRules(rules Rules) *Game {
 
 	game := &Game{}
+	game.Rules = rules
 	game.Tiles = randomizedTiles()
 
 	game.Players = [MAX_PLAYERS]Player{}
 	for i := 1; i <= MAX_PLAYERS; i++ {
 		game.Players[i-1] = Player{
 			Id:     i,
-			Money:  6000,
+			Money:  rules.StartingMoney,
 			Tiles:  [MAX_TILES_IN_HAND]Tile{},
 			Stocks: [NUM_CHAINS]int{},
 		}
 	}
 
 	for i := 0; i < NUM_CHAINS; i++ {
-		game.Stocks[i] = 25
+		game.Stocks[i] = TOTAL_STOCKS
 	}
 
 	for idx := range game.Players {
@@ -94,6 +123,7 @@ func NewGame() *Game {
 	}
 
 	game.Computed = NewComputed(game)
+	game.RecomputeHash()
 
 	return game
 }
@@ -200,11 +230,11 @@ func (game *Game) payShareholderBonuses(hotel Hotel) {
 
 	// only apply money modification when the player is the active player to avoid triggering twice
 	if majShareholder.Id == game.ActivePlayer().Id {
-		majShareholder.Money += majBonus
+		majShareholder.receive(game, majBonus)
 	}
 
 	if minorShareholder.Id == game.ActivePlayer().Id {
-		minorShareholder.Money += minBonus
+		minorShareholder.receive(game, minBonus)
 	}
 }
 
@@ -258,10 +288,17 @@ func (game *Game) PlacementAtPos(pt util.Point[int]) PlacedHotel {
 	return game.Board[idx]
 }
 
+// placeTileOnBoard
+// puts a tile which isn't on the board yet onto the board, joining it with neighbors of the same hotel.
+// to change the hotel of a tile already on the board, use propagateHotelChain
 func (game *Game) placeTileOnBoard(tile Tile, hotel Hotel) PlacedHotel {
 	newPlacedHotel := PlacedHotel{Tile: tile, Hotel: hotel}
 	game.Board[tile.Index()] = newPlacedHotel
+	game.setBitboardHotel(tile.Index(), NoHotel, hotel)
+	game.hash ^= cellKey(tile.Index(), hotel)
 	game.LastPlacedTile = tile
+	game.placeInSet(tile.Index())
+	game.markLegalMovesDirty()
 
 	if hotel != NoHotel && hotel != UndefinedHotel {
 		game.modifyChainSize(hotel, 1)
@@ -270,6 +307,20 @@ func (game *Game) placeTileOnBoard(tile Tile, hotel Hotel) PlacedHotel {
 	return newPlacedHotel
 }
 
+// SetPlayerIdentity
+// names the player with the given id and describes who is playing them
+func (game *Game) SetPlayerIdentity(id int, name string, agent string) error {
+	for i := range game.Players {
+		if game.Players[i].Id == id {
+			game.Players[i].DisplayName = name
+			game.Players[i].Agent = agent
+			return nil
+		}
+	}
+
+	return fmt.Errorf("there is no player with id %d", id)
+}
+
 func (game *Game) GetPlayerById(id int) *Player {
 	for _, p := range game.Players {
 		if p.Id == id {
@@ -282,6 +333,10 @@ func (game *Game) GetPlayerById(id int) *Player {
 
 // modifyChainSize
 // just want to keep track of these in a func, easier to track down usage
+// (and the computed chain values depend on it)
 func (game *Game) modifyChainSize(hotel Hotel, amount int) {
+	before := game.ChainSize[hotel.Index()]
 	game.ChainSize[hotel.Index()] += amount
+
+	game.Computed.chainSizeChanged(game, hotel, before)
 }
@@ -30,10 +30,14 @@ func TestPlaceTileOnBoard(t *testing.T) {
 }
 
 func TestPlaceTileActions(t *testing.T) {
-	tileA := Tile4D
-	tileB := Tile5D
-
-	game := NewGame()
+	game, err := ParsePosition(`
+		player 1: tiles 5D
+		   1  2  3  4  5  6  7  8  9  10 11 12
+		d  □  □  □  ■  □  □  □  □  □  □  □  □
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	doAction := func(action gmcts.Action) {
 		newGame, err := game.ApplyAction(action)
@@ -45,24 +49,23 @@ func TestPlaceTileActions(t *testing.T) {
 		game = newGame.(*Game)
 	}
 
-	game.placeTileOnBoard(tileA, UndefinedHotel)
-
-	game.CurrentPlayer().Tiles[0] = tileB
-
-	game.Computed = NewComputed(game)
-
 	doAction(Action_PlaceTile{
-		Tile: tileB,
+		Tile: Tile5D,
 		End:  false,
 	})
 
-	// purchase stock action
+	if game.NextActionType != ActionType_PickHotelToFound {
+		t.Fatal("placing a tile next to a loose tile should found a chain")
+	}
+
+	// found a hotel, then purchase stock
 	actions := game.GetActions()
 	doAction(actions[0])
 
 	actions = game.GetActions()
 	doAction(actions[0])
 
-	print(actions)
-
+	if game.ChainSize[WorldwideHotel.Index()] != 2 || game.CurrentPlayer().Id != 2 {
+		t.Fatal("worldwide should have been founded, and it should be the next player's turn")
+	}
 }
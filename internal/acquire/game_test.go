@@ -34,3 +34,21 @@ func TestComparison(t *testing.T) {
 	}
 
 }
+
+func TestEnd(t *testing.T) {
+	game, err := ParsePosition(`player 1: $1000, stocks W2 / player 2: $1000, stocks W1 / a W W □ □ □ □ □ □ □ □ □ □`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game.end("no tiles left")
+
+	if !game.IsTerminal() || game.EndReason != "no tiles left" {
+		t.Fatalf("the game should be over with its reason, ended: %v %q", game.IsTerminal(), game.EndReason)
+	}
+
+	// everyone sells up at the end
+	if game.GetPlayerById(1).Stocks[WorldwideHotel.Index()] != 0 || game.Stocks[WorldwideHotel.Index()] != TOTAL_STOCKS {
+		t.Error("every share should be back in the bank")
+	}
+}
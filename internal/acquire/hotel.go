@@ -95,3 +95,37 @@ func (h Hotel) Value(game *Game, amount int) int {
 	size := game.ChainSize[h.Index()]
 	return sharesCalc(h, size, amount)
 }
+
+// hotelSet
+// a small set of unique hotels, used instead of a slice where allocating would be too slow.
+// a tile only has four neighbors, so four is enough for anything to do with a single placement
+type hotelSet struct {
+	hotels [4]Hotel
+	len    int
+}
+
+// add
+// adds the hotel if it isn't already in the set
+func (s *hotelSet) add(hotel Hotel) {
+	if s.contains(hotel) {
+		return
+	}
+
+	s.hotels[s.len] = hotel
+	s.len++
+}
+
+func (s *hotelSet) contains(hotel Hotel) bool {
+	for i := 0; i < s.len; i++ {
+		if s.hotels[i] == hotel {
+			return true
+		}
+	}
+	return false
+}
+
+// slice
+// the hotels in the set, in the order they were added
+func (s *hotelSet) slice() []Hotel {
+	return s.hotels[:s.len]
+}
@@ -0,0 +1,222 @@
+package acquire
+
+import "fmt"
+
+// CheckInvariants
+// checks that the game is in a consistent state, returning an error describing the first problem found.
+// this is too slow to run on every action during a search, so Apply only does it in debug builds
+// (go build -tags debug), but tests can call it whenever they like
+func (game *Game) CheckInvariants() error {
+	checks := []func() error{
+		game.checkStocks,
+		game.checkChainSizes,
+		game.checkTiles,
+		game.checkMoney,
+		game.checkMergerState,
+		game.checkLastPlacedTile,
+		game.checkCaches,
+	}
+
+	for _, check := range checks {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkStocks
+// every share of every chain is either in the bank or held by a player
+func (game *Game) checkStocks() error {
+	for idx, hotel := range HotelChainList {
+		total := game.Stocks[idx]
+		for _, p := range game.Players {
+			if p.Stocks[idx] < 0 {
+				return fmt.Errorf("%s holds %d shares of %s", p.Name(), p.Stocks[idx], hotel.String())
+			}
+			total += p.Stocks[idx]
+		}
+
+		if game.Stocks[idx] < 0 || total != TOTAL_STOCKS {
+			return fmt.Errorf("there are %d shares of %s (%d in the bank), should be %d",
+				total, hotel.String(), game.Stocks[idx], TOTAL_STOCKS)
+		}
+	}
+
+	return nil
+}
+
+// checkChainSizes
+// the size of each chain is the number of tiles it has on the board
+func (game *Game) checkChainSizes() error {
+	var counts [NUM_CHAINS]int
+	for _, placed := range game.Board {
+		if placed.Hotel != NoHotel && placed.Hotel != UndefinedHotel {
+			counts[placed.Hotel.Index()]++
+		}
+	}
+
+	for idx, hotel := range HotelChainList {
+		if counts[idx] != game.ChainSize[idx] {
+			return fmt.Errorf("%s has %d tiles on the board, but its size is %d", hotel.String(), counts[idx], game.ChainSize[idx])
+		}
+	}
+
+	return nil
+}
+
+// checkTiles
+// every tile is in exactly one place, either on the board, in someone's hand or in the bank
+func (game *Game) checkTiles() error {
+	var places [BOARD_MAX_X * BOARD_MAX_Y]string
+
+	put := func(tile Tile, place string) error {
+		idx := tile.Index()
+		if places[idx] != "" {
+			return fmt.Errorf("%s is both %s and %s", tile.String(), places[idx], place)
+		}
+		places[idx] = place
+		return nil
+	}
+
+	for idx, placed := range game.Board {
+		if placed.Hotel == NoHotel {
+			continue
+		}
+
+		if placed.Tile != TileFromBoardIdx(idx) {
+			return fmt.Errorf("%s is on the board at %s", placed.Tile.String(), TileFromBoardIdx(idx).String())
+		}
+
+		if err := put(placed.Tile, "on the board"); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range game.Players {
+		for _, tile := range p.Tiles {
+			if tile == NoTile {
+				continue
+			}
+
+			if err := put(tile, "in "+p.Name()+"'s hand"); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, tile := range game.Tiles {
+		if tile == NoTile {
+			continue
+		}
+
+		if err := put(tile, "in the bank"); err != nil {
+			return err
+		}
+	}
+
+	for idx, place := range places {
+		if place == "" {
+			return fmt.Errorf("%s is missing", TileFromBoardIdx(idx).String())
+		}
+	}
+
+	return nil
+}
+
+func (game *Game) checkMoney() error {
+	for _, p := range game.Players {
+		if p.Money < 0 {
+			return fmt.Errorf("%s has $%d", p.Name(), p.Money)
+		}
+	}
+
+	return nil
+}
+
+// checkMergerState
+// there's a merger underway exactly when it's someone's merge action, and it's between chains on the board
+func (game *Game) checkMergerState() error {
+	mergingHotel, merging := game.getNextChainToMerge()
+
+	if game.NextActionType != ActionType_Merge {
+		if merging && !game.IsOver {
+			return fmt.Errorf("%s is still being merged, but the next action is %s",
+				mergingHotel.String(), game.NextActionType.String())
+		}
+		return nil
+	}
+
+	if !merging {
+		return fmt.Errorf("the next action is %s, but there are no chains left to merge", game.NextActionType.String())
+	}
+
+	state := game.MergerState
+	if state.MergingPlayerIdx < 0 || state.MergingPlayerIdx >= game.numRealPlayers() {
+		return fmt.Errorf("player %d is merging, but there are %d players", state.MergingPlayerIdx, game.numRealPlayers())
+	}
+
+	acquiring := state.AcquiringHotel
+	if acquiring == NoHotel || acquiring == UndefinedHotel || game.ChainSize[acquiring.Index()] == 0 {
+		return fmt.Errorf("%s is acquiring, but it isn't a chain on the board", acquiring.String())
+	}
+
+	if state.ChainsToMerge[acquiring.Index()] != 0 {
+		return fmt.Errorf("%s is acquiring, but it's also being merged", acquiring.String())
+	}
+
+	for idx, playersRemaining := range state.ChainsToMerge {
+		hotel := ChainFromIdx(idx)
+		if playersRemaining < 0 || playersRemaining > game.numRealPlayers() {
+			return fmt.Errorf("%s has %d players left to merge it", hotel.String(), playersRemaining)
+		}
+
+		if playersRemaining > 0 && game.ChainSize[idx] == 0 {
+			return fmt.Errorf("%s is being merged, but it isn't a chain on the board", hotel.String())
+		}
+	}
+
+	if game.Board[game.LastPlacedTile.Index()].Hotel != UndefinedHotel {
+		return fmt.Errorf("%s caused the merger, but is already part of %s",
+			game.LastPlacedTile.String(), game.Board[game.LastPlacedTile.Index()].Hotel.String())
+	}
+
+	return nil
+}
+
+// checkLastPlacedTile
+// the last placed tile is on the board (if one has been placed at all)
+func (game *Game) checkLastPlacedTile() error {
+	if game.LastPlacedTile == NoTile {
+		return nil
+	}
+
+	if game.Board[game.LastPlacedTile.Index()].Hotel == NoHotel {
+		return fmt.Errorf("%s was the last tile placed, but it isn't on the board", game.LastPlacedTile.String())
+	}
+
+	return nil
+}
+
+// checkCaches
+// the values kept up to date alongside Board (the tile sets, bitboards and hash) agree with it
+func (game *Game) checkCaches() error {
+	for idx, placed := range game.Board {
+		tile := TileFromBoardIdx(idx)
+
+		if hotel, _ := game.ChainAt(tile); hotel != placed.Hotel {
+			return fmt.Errorf("the tile set at %s is %s, but the board has %s", tile.String(), hotel.String(), placed.Hotel.String())
+		}
+
+		if game.Occupied().Has(idx) != (placed.Hotel != NoHotel) || !game.ChainBitboard(placed.Hotel).Has(idx) {
+			return fmt.Errorf("the bitboards at %s don't match the board's %s", tile.String(), placed.Hotel.String())
+		}
+	}
+
+	if game.hash != game.computeHash() {
+		return fmt.Errorf("the hash is %x, should be %x", game.hash, game.computeHash())
+	}
+
+	return nil
+}
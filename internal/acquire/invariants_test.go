@@ -0,0 +1,62 @@
+package acquire
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestInvariantsHoldInRandomGames
+// plays random games, checking the invariants in every state
+func TestInvariantsHoldInRandomGames(t *testing.T) {
+	rand.Seed(5)
+	states, actions := recordRandomGames(50)
+
+	for i := range states {
+		if err := states[i].CheckInvariants(); err != nil {
+			t.Fatalf("state %d (before %s): %s", i, actions[i].String(&states[i]), err)
+		}
+	}
+}
+
+func TestInvariantsCatchProblems(t *testing.T) {
+	rand.Seed(6)
+	states, _ := recordRandomGames(1)
+
+	// a state part way through, with chains on the board
+	game := &states[len(states)/2]
+	chain := game.Computed.ActiveChains()[0]
+
+	if err := game.CheckInvariants(); err != nil {
+		t.Fatalf("the game should be consistent, got: %s", err)
+	}
+
+	tests := map[string]func(g *Game){
+		"a share going missing": func(g *Game) {
+			g.Stocks[chain.Index()]--
+		},
+		"the wrong chain size": func(g *Game) {
+			g.ChainSize[chain.Index()]++
+		},
+		"a tile in two places": func(g *Game) {
+			g.Players[0].Tiles[0] = g.Players[1].Tiles[0]
+		},
+		"negative money": func(g *Game) {
+			g.Players[2].Money = -100
+		},
+		"a merger with nothing to merge": func(g *Game) {
+			g.NextActionType = ActionType_Merge
+		},
+		"the last placed tile not being on the board": func(g *Game) {
+			g.LastPlacedTile = g.Players[0].Tiles[0]
+		},
+	}
+
+	for name, modify := range tests {
+		g := *game
+		modify(&g)
+
+		if err := g.CheckInvariants(); err == nil {
+			t.Errorf("%s should break the invariants", name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package acquire
+
+import (
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"strconv"
+	"strings"
+)
+
+// Notation
+// a short name for the action with no spaces, for passing moves between programs:
+//
+//	5C               place tile 5C
+//	skip             skip placing a tile (when there's nothing legal to place)
+//	found:T          found Tower
+//	acquirer:T       pick Tower as the acquiring chain in a tied merger
+//	hold             hold on to all shares of the chain being merged
+//	sell:3           sell 3 shares of the chain being merged (and hold the rest)
+//	trade:4,sell:1   trade 4 shares for 2 in the acquirer, and sell 1
+//	buy:W2,T1        buy 2 shares of Worldwide and 1 of Tower
+//	pass             buy nothing
+//
+// chains are given by their initial. the notation doesn't depend on the game, but it only picks out an action
+// among the actions available at the time (see FindAction)
+func Notation(action gmcts.Action) string {
+	switch a := action.(type) {
+	case Action_PlaceTile:
+		if a.Tile == NoTile {
+			return "skip"
+		}
+		return a.Tile.String()
+
+	case Action_PickHotelToFound:
+		return "found:" + a.Hotel.Initial()
+
+	case Action_PickHotelToMerge:
+		return "acquirer:" + a.Hotel.Initial()
+
+	case Action_Merge:
+		parts := make([]string, 0, MAX_MERGE_SUB_ACTIONS)
+		for _, sub := range a.Actions {
+			if sub.MergeType != Hold && sub.Amount > 0 {
+				parts = append(parts, strings.ToLower(sub.MergeType.String())+":"+strconv.Itoa(sub.Amount))
+			}
+		}
+
+		if len(parts) == 0 {
+			return "hold"
+		}
+		return strings.Join(parts, ",")
+
+	case Action_PurchaseStock:
+		purchases := a.AsMap()
+
+		parts := make([]string, 0, len(purchases))
+		for _, hotel := range HotelChainList {
+			if purchases[hotel] > 0 {
+				parts = append(parts, hotel.Initial()+strconv.Itoa(purchases[hotel]))
+			}
+		}
+
+		if len(parts) == 0 {
+			return "pass"
+		}
+		return "buy:" + strings.Join(parts, ",")
+
+	default:
+		panic(fmt.Sprintf("no notation for %T", action))
+	}
+}
+
+// FindAction
+// the action with the given notation (ignoring case) among the actions
+func FindAction(actions []gmcts.Action, notation string) (gmcts.Action, error) {
+	notation = strings.TrimSpace(notation)
+
+	for _, action := range actions {
+		if strings.EqualFold(Notation(action), notation) {
+			return action, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%q isn't one of the legal actions", notation)
+}
@@ -0,0 +1,56 @@
+package acquire
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNotation(t *testing.T) {
+	tests := map[string]IAction{
+		"5C":             Action_PlaceTile{Tile: Tile5C},
+		"skip":           Action_PlaceTile{Tile: NoTile},
+		"found:T":        Action_PickHotelToFound{Hotel: TowerHotel},
+		"acquirer:W":     Action_PickHotelToMerge{Hotel: WorldwideHotel},
+		"hold":           Action_Merge{},
+		"sell:3":         Action_Merge{Actions: [MAX_MERGE_SUB_ACTIONS]MergeSubAction{{MergeType: Sell, Amount: 3}}},
+		"trade:4,sell:1": Action_Merge{Actions: [MAX_MERGE_SUB_ACTIONS]MergeSubAction{{MergeType: Trade, Amount: 4}, {MergeType: Sell, Amount: 1}}},
+		"pass":           Action_PurchaseStock{},
+		"buy:W2,T1": Action_PurchaseStock{Purchases: [3]StockPurchase{
+			{Hotel: TowerHotel, Amount: 1},
+			{Hotel: WorldwideHotel, Amount: 1},
+			{Hotel: WorldwideHotel, Amount: 1},
+		}},
+	}
+
+	for expected, action := range tests {
+		if notation := Notation(action); notation != expected {
+			t.Errorf("expected %s, got %s", expected, notation)
+		}
+	}
+}
+
+// TestFindAction
+// finding the notation of an action among the legal actions gives an action which does the same thing
+func TestFindAction(t *testing.T) {
+	rand.Seed(8)
+	states, actions := recordRandomGames(5)
+
+	for i := range states {
+		game := states[i]
+
+		found, err := FindAction(game.GetActions(), Notation(actions[i]))
+		if err != nil {
+			t.Fatalf("state %d: %s", i, err)
+		}
+
+		expected, _ := game.ApplyAction(actions[i])
+		got, _ := game.ApplyAction(found)
+		if got.(*Game).Hash() != expected.(*Game).Hash() {
+			t.Fatalf("state %d: %s found an action which does something else", i, Notation(actions[i]))
+		}
+	}
+
+	if _, err := FindAction(states[0].GetActions(), "found:T"); err == nil {
+		t.Error("an action which isn't legal shouldn't be found")
+	}
+}
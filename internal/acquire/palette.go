@@ -0,0 +1,76 @@
+package acquire
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Palette
+// the colors the ANSIRenderer draws the chains in
+type Palette int
+
+const (
+	// the colors of the chains on the board game
+	PaletteClassic Palette = iota
+	// the Okabe-Ito colors, which stay apart for the common kinds of color blindness
+	PaletteColorBlind
+)
+
+var paletteNames = []string{"classic", "colorblind"}
+
+// chainColor
+// a chain's background, and the text color that stands out on it, as xterm 256 color numbers
+type chainColor struct {
+	bg int
+	fg int
+}
+
+const (
+	black = 16
+	white = 231
+)
+
+// the colors of each palette, in HotelChainList order
+var paletteColors = [][]chainColor{
+	PaletteClassic: {
+		{94, white},  // Worldwide, brown
+		{160, white}, // Sackson, red
+		{28, white},  // Festival, green
+		{208, black}, // Imperial, orange
+		{26, white},  // American, blue
+		{37, black},  // Continental, teal
+		{220, black}, // Tower, yellow
+	},
+	PaletteColorBlind: {
+		{166, white}, // Worldwide, vermillion
+		{175, black}, // Sackson, reddish purple
+		{36, black},  // Festival, bluish green
+		{214, black}, // Imperial, orange
+		{25, white},  // American, blue
+		{74, black},  // Continental, sky blue
+		{227, black}, // Tower, yellow
+	},
+}
+
+func (p Palette) String() string {
+	return paletteNames[p]
+}
+
+// ParsePalette
+// the palette with the given name, as from String
+func ParsePalette(s string) (Palette, error) {
+	for p, name := range paletteNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return Palette(p), nil
+		}
+	}
+
+	return PaletteClassic, fmt.Errorf("unknown palette '%s', expected %s", s, strings.Join(paletteNames, ", "))
+}
+
+// Paint
+// the text on the chain's color
+func (p Palette) Paint(hotel Hotel, s string) string {
+	color := paletteColors[p][hotel.Index()]
+	return fmt.Sprintf("\033[1;38;5;%d;48;5;%dm%s\033[0m", color.fg, color.bg, s)
+}
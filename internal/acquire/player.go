@@ -7,16 +7,45 @@ import (
 	"strconv"
 )
 
+// errors which happen during normal play (such as the bank running out of tiles) are made once up front,
+// so that hitting them doesn't allocate
+var (
+	errBankEmpty = errors.New("player cannot take a tile from the bank, the bank has no tiles remaining")
+	errHandFull  = errors.New("player cannot take a tile from the bank, their hand is full")
+)
+
 type Player struct {
-	Id    int
+	Id int
+
+	// what the player is called, optional
+	DisplayName string
+	// who (or what) is playing this seat, e.g. "Human" or "MCTS-500", optional
+	Agent string
+
 	Money int
 	Tiles [MAX_TILES_IN_HAND]Tile
 	// mapped to hotels/stocks
 	Stocks [NUM_CHAINS]int
 }
 
+// Name
+// the display name of the player, or 'Player n' if they don't have one
 func (player *Player) Name() string {
-	return strconv.Itoa(player.Id)
+	if player.DisplayName != "" {
+		return player.DisplayName
+	}
+
+	return "Player " + strconv.Itoa(player.Id)
+}
+
+// Description
+// the player's name along with who is playing them, e.g. "Alice (Human)"
+func (player *Player) Description() string {
+	if player.Agent == "" {
+		return player.Name()
+	}
+
+	return fmt.Sprintf("%s (%s)", player.Name(), player.Agent)
 }
 
 // removeTileFromHand
@@ -47,7 +76,7 @@ func (player *Player) takeTileFromBank(game *Game) error {
 	}
 
 	if tile == NoTile {
-		return errors.New("player cannot take a tile from the bank, the bank has no tiles remaining")
+		return errBankEmpty
 	}
 
 	// put the tile in the first empty slot
@@ -57,11 +86,12 @@ func (player *Player) takeTileFromBank(game *Game) error {
 
 			// only set the bank
 			game.Tiles[bankIdx] = NoTile
+			game.markLegalMovesDirty()
 			return nil
 		}
 	}
 
-	return errors.New("player cannot take a tile from the bank, their hand is full")
+	return errHandFull
 }
 
 func (player *Player) returnTileToBank(game *Game, tile Tile) error {
@@ -86,6 +116,7 @@ func (player *Player) returnTileToBank(game *Game, tile Tile) error {
 	}
 
 	game.Tiles[bankIdx] = tile
+	game.markLegalMovesDirty()
 
 	return nil
 }
@@ -97,8 +128,10 @@ func (player *Player) takeStockFromBank(game *Game, chain Hotel, amount int) err
 		return errors.New("can't take stock from bank, not enough stock in bank to take")
 	}
 
+	before := player.Stocks[idx]
 	game.Stocks[idx] -= amount
 	player.Stocks[idx] += amount
+	game.stocksChanged(player, idx, before)
 
 	return nil
 }
@@ -110,8 +143,10 @@ func (player *Player) giveStockToBank(game *Game, chain Hotel, amount int) error
 		return errors.New("can't give stock to bank, player does not have the requested amount to give")
 	}
 
+	before := player.Stocks[idx]
 	game.Stocks[idx] += amount
 	player.Stocks[idx] -= amount
+	game.stocksChanged(player, idx, before)
 
 	return nil
 }
@@ -185,7 +220,7 @@ func (player *Player) sellStock(game *Game, stock Stock, amount int) error {
 	chainSize := game.ChainSize[hotel.Index()]
 	value := sharesCalc(hotel, chainSize, amount)
 
-	player.Money += value
+	player.receive(game, value)
 
 	return nil
 }
@@ -200,7 +235,7 @@ func (player *Player) buyStock(game *Game, hotel Hotel, amount int) error {
 
 	cost := sharesCalc(hotel, chainSize, amount)
 
-	err := player.pay(cost)
+	err := player.pay(game, cost)
 	if err != nil {
 		return err
 	}
@@ -218,16 +253,24 @@ func (player *Player) buyStock(game *Game, hotel Hotel, amount int) error {
 	return nil
 }
 
-func (player *Player) pay(amount int) error {
+func (player *Player) pay(game *Game, amount int) error {
 	if player.Money < amount {
-		return fmt.Errorf("player '%d' cannot afford to pay $%d", player.Id, amount)
+		return fmt.Errorf("%s cannot afford to pay $%d", player.Name(), amount)
 	}
 
+	before := player.Money
 	player.Money -= amount
+	game.moneyChanged(player, before)
 
 	return nil
 }
 
+func (player *Player) receive(game *Game, amount int) {
+	before := player.Money
+	player.Money += amount
+	game.moneyChanged(player, before)
+}
+
 // refreshTiles
 // when a player has no legal moves left to play, they can refresh their hand with this func
 // puts all tiles back in the Game inv, then takes 6 new ones
@@ -254,6 +297,15 @@ func (player *Player) refreshTiles(game *Game) {
 	}
 }
 
+// NetWorth
+// the player's money plus the value of their stock in the chains on the board.
+// cheap enough that it's worked out when asked for, rather than kept up to date
 func (player *Player) NetWorth(game *Game) int {
-	return game.Computed.PlayerNetWorth[player.Id]
+	netWorth := player.Money
+	for idx, size := range game.ChainSize {
+		if size > 0 {
+			netWorth += sharesCalc(ChainFromIdx(idx), size, player.Stocks[idx])
+		}
+	}
+	return netWorth
 }
@@ -0,0 +1,39 @@
+package acquire
+
+import "testing"
+
+func TestPlayerIdentity(t *testing.T) {
+	tests := []struct {
+		name        string
+		displayName string
+		agent       string
+		expected    string
+		description string
+	}{
+		{"default name", "", "", "Player 2", "Player 2"},
+		{"custom name", "Alice", "", "Alice", "Alice"},
+		{"agent without a name", "", "MCTS-500", "Player 2", "Player 2 (MCTS-500)"},
+		{"agent with a name", "Alice", "Human", "Alice", "Alice (Human)"},
+	}
+
+	for _, test := range tests {
+		game := NewGame()
+		err := game.SetPlayerIdentity(2, test.displayName, test.agent)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		player := game.GetPlayerById(2)
+		if player.Name() != test.expected || player.Description() != test.description {
+			t.Errorf("%s: expected %q and %q, got %q and %q", test.name, test.expected, test.description, player.Name(), player.Description())
+		}
+
+		if other := game.GetPlayerById(1); other.DisplayName != "" || other.Agent != "" {
+			t.Errorf("%s: only player 2 should have been changed", test.name)
+		}
+	}
+
+	if err := NewGame().SetPlayerIdentity(MAX_PLAYERS+1, "Nobody", ""); err == nil {
+		t.Error("players which don't exist can't be named")
+	}
+}
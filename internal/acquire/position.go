@@ -0,0 +1,824 @@
+package acquire
+
+import (
+	"acquire/internal/util"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// ParsePosition
+// builds a game from a text description of a position, so that scenarios can be written out rather than
+// set up by hand. the board is the same grid that's rendered, with a header above it:
+//
+//	players: 3
+//	turn: 4
+//	phase: purchase stock
+//	last: 5D
+//	player 1: $5400, tiles 1A 7F, stocks W3 T2
+//	player 2: $6000
+//	   1  2  3  4  5  6  7  8  9  10 11 12
+//	a  W  W  □  □  □  □  □  □  □  □  □  □
+//	b  □  □  □  □  ■  □  □  □  □  □  □  □
+//
+// on the board a hotel's initial is a tile in that chain, ■ is a tile which isn't in a chain yet and □ (or ○ or .)
+// is empty. rows which are left out are empty, and the numbers along the top are optional.
+//
+// every header line is optional. players defaults to the highest player mentioned (at least 2), turn to 0
+// and phase to place tile. players start with the rules' starting money and no stocks, and anyone without
+// tiles is dealt a hand from the rest of the tiles, shuffled with math/rand.
+//
+// for the merge and pick hotel to merge phases, last is the tile which started the merger (it should still be
+// a ■ on the board), and for the merge phase, "acquirer: T" picks the acquiring chain when the largest are tied.
+// a merge phase starts at the beginning of the merger, unless it's picked up part way through with
+// "merging: S2 F3" (the chains still to be merged, with how many players have yet to deal with each)
+// and "merging player: 2".
+//
+// the rest of the game's state has headers of its own, which Position only writes when they're set:
+// "skipped turns: 2" (the turns skipped in a row), "will end: true", "over: "no tiles left"" (a finished game,
+// and why it ended), and "player 1 name: "Alice"" and "player 1 agent: "MCTS-500"". names and reasons are
+// quoted as in go, with any / escaped. a game which isn't written as over, but would end as soon as it was
+// played on (see checkOver), is read as over. lines starting with # are comments, and lines can also be separated by a /
+// (see OneLinePosition)
+func ParsePosition(text string) (*Game, error) {
+	return ParsePositionWithRules(text, DefaultRules)
+}
+
+// ParsePositionWithRules
+// the same as ParsePosition, but played with rule variants
+func ParsePositionWithRules(text string, rules Rules) (*Game, error) {
+	p := positionParser{
+		rules:      rules,
+		numPlayers: 0,
+		phase:      ActionType_PlaceTile,
+		last:       NoTile,
+		acquirer:   NoHotel,
+	}
+
+	for n, line := range strings.Split(strings.ReplaceAll(text, "/", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var err error
+		if strings.Contains(line, ":") {
+			err = p.parseHeader(line)
+		} else {
+			err = p.parseBoardRow(line)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+
+	return p.build()
+}
+
+type positionPlayer struct {
+	money    int
+	hasMoney bool
+	tiles    []Tile
+	hasTiles bool
+	stocks   [NUM_CHAINS]int
+	name     string
+	agent    string
+}
+
+type positionParser struct {
+	rules Rules
+
+	// zero when not given
+	numPlayers int
+	// the highest numbered player line
+	highestPlayer int
+	turn          int
+	phase         ActionType
+	last          Tile
+	acquirer      Hotel
+
+	// how far through the merger it is, when given
+	merging       [NUM_CHAINS]int
+	hasMerging    bool
+	mergingPlayer int
+
+	skippedTurns int
+	willEnd      bool
+	isOver       bool
+	endReason    string
+
+	players [MAX_PLAYERS]positionPlayer
+	board   [BOARD_MAX_X * BOARD_MAX_Y]Hotel
+}
+
+func (p *positionParser) parseHeader(line string) error {
+	key, value, _ := strings.Cut(line, ":")
+	key = strings.ToLower(strings.TrimSpace(key))
+	value = strings.TrimSpace(value)
+
+	switch {
+	case key == "players":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 2 || n > MAX_PLAYERS {
+			return fmt.Errorf("players must be a number from 2 to %d, was %q", MAX_PLAYERS, value)
+		}
+		p.numPlayers = n
+
+	case key == "turn":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("turn must be a number, was %q", value)
+		}
+		p.turn = n
+
+	case key == "phase":
+		phase, err := parseActionType(value)
+		if err != nil {
+			return err
+		}
+		p.phase = phase
+
+	case key == "last":
+		tile, err := TileFromString(value)
+		if err != nil {
+			return err
+		}
+		p.last = tile
+
+	case key == "acquirer":
+		hotel, err := ChainFromInitial(strings.ToUpper(value))
+		if err != nil || hotel == NoHotel || hotel == UndefinedHotel {
+			return fmt.Errorf("acquirer must be a chain's initial, was %q", value)
+		}
+		p.acquirer = hotel
+
+	case key == "merging":
+		p.merging = [NUM_CHAINS]int{}
+		for _, s := range strings.Fields(value) {
+			hotel, err := ChainFromInitial(strings.ToUpper(s[:1]))
+			if err != nil || hotel == NoHotel || hotel == UndefinedHotel {
+				return fmt.Errorf("merging: %q should be a chain's initial followed by a number of players", s)
+			}
+
+			remaining, err := strconv.Atoi(s[1:])
+			if err != nil || remaining < 0 || remaining > MAX_PLAYERS {
+				return fmt.Errorf("merging: %q should be a chain's initial followed by a number of players", s)
+			}
+			p.merging[hotel.Index()] = remaining
+		}
+		p.hasMerging = true
+
+	case key == "merging player":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MAX_PLAYERS {
+			return fmt.Errorf("merging player must be a player from 1 to %d, was %q", MAX_PLAYERS, value)
+		}
+		p.mergingPlayer = n
+
+	case key == "skipped turns":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("skipped turns must be a number, was %q", value)
+		}
+		p.skippedTurns = n
+
+	case key == "will end":
+		willEnd, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("will end must be true or false, was %q", value)
+		}
+		p.willEnd = willEnd
+
+	case key == "over":
+		reason, err := unquote(value)
+		if err != nil {
+			return fmt.Errorf("over: %w", err)
+		}
+		p.isOver = true
+		p.endReason = reason
+
+	case strings.HasPrefix(key, "player "):
+		number, attribute, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(key, "player ")), " ")
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 1 || n > MAX_PLAYERS {
+			return fmt.Errorf("%q isn't a player from 1 to %d", key, MAX_PLAYERS)
+		}
+
+		switch strings.TrimSpace(attribute) {
+		case "":
+			return p.parsePlayer(n, value)
+		case "name":
+			return p.parsePlayerIdentity(n, &p.players[n-1].name, value)
+		case "agent":
+			return p.parsePlayerIdentity(n, &p.players[n-1].agent, value)
+		default:
+			return fmt.Errorf("unknown header %q", key)
+		}
+
+	default:
+		return fmt.Errorf("unknown header %q", key)
+	}
+
+	return nil
+}
+
+// parsePlayer
+// a comma separated list of "$money", "tiles 1A 2B" and "stocks W3 T2", in any order
+func (p *positionParser) parsePlayer(n int, value string) error {
+	player := &p.players[n-1]
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		fields := strings.Fields(part)
+
+		switch {
+		case part == "":
+			continue
+
+		case strings.HasPrefix(part, "$"):
+			money, err := strconv.Atoi(strings.TrimPrefix(part, "$"))
+			if err != nil || money < 0 {
+				return fmt.Errorf("player %d: %q isn't an amount of money", n, part)
+			}
+			player.money = money
+			player.hasMoney = true
+
+		case fields[0] == "tiles":
+			if len(fields)-1 > MAX_TILES_IN_HAND {
+				return fmt.Errorf("player %d: can't have more than %d tiles", n, MAX_TILES_IN_HAND)
+			}
+
+			player.tiles = player.tiles[:0]
+			for _, s := range fields[1:] {
+				tile, err := TileFromString(s)
+				if err != nil {
+					return fmt.Errorf("player %d: %w", n, err)
+				}
+				player.tiles = append(player.tiles, tile)
+			}
+			player.hasTiles = true
+
+		case fields[0] == "stocks":
+			for _, s := range fields[1:] {
+				hotel, err := ChainFromInitial(strings.ToUpper(s[:1]))
+				if err != nil || hotel == NoHotel || hotel == UndefinedHotel {
+					return fmt.Errorf("player %d: %q should be a chain's initial followed by a number of shares", n, s)
+				}
+
+				amount, err := strconv.Atoi(s[1:])
+				if err != nil || amount < 0 {
+					return fmt.Errorf("player %d: %q should be a chain's initial followed by a number of shares", n, s)
+				}
+				player.stocks[hotel.Index()] = amount
+			}
+
+		default:
+			return fmt.Errorf("player %d: %q should be $money, tiles or stocks", n, part)
+		}
+	}
+
+	if n > p.highestPlayer {
+		p.highestPlayer = n
+	}
+
+	return nil
+}
+
+// parsePlayerIdentity
+// the quoted name or agent of player n, read into s
+func (p *positionParser) parsePlayerIdentity(n int, s *string, value string) error {
+	unquoted, err := unquote(value)
+	if err != nil {
+		return fmt.Errorf("player %d: %w", n, err)
+	}
+	*s = unquoted
+
+	if n > p.highestPlayer {
+		p.highestPlayer = n
+	}
+
+	return nil
+}
+
+// quote
+// the string quoted for a header, with any / escaped so that it isn't taken for the end of the line
+func quote(s string) string {
+	return strings.ReplaceAll(strconv.Quote(s), "/", `\u002f`)
+}
+
+// unquote
+// reads a string written by quote
+func unquote(value string) (string, error) {
+	s, err := strconv.Unquote(value)
+	if err != nil {
+		return "", fmt.Errorf("%s should be a quoted string", value)
+	}
+	return s, nil
+}
+
+// parseBoardRow
+// either the numbered header, or a row letter followed by a cell for each column
+func (p *positionParser) parseBoardRow(line string) error {
+	fields := strings.Fields(line)
+
+	if _, err := strconv.Atoi(fields[0]); err == nil {
+		return nil
+	}
+
+	y := -1
+	for i, c := range chars {
+		if strings.EqualFold(fields[0], c) {
+			y = i
+		}
+	}
+
+	if y < 0 {
+		return fmt.Errorf("%q isn't a row of the board", fields[0])
+	}
+
+	cells := fields[1:]
+	if len(cells) != BOARD_MAX_X {
+		return fmt.Errorf("row %s has %d cells, should have %d", chars[y], len(cells), BOARD_MAX_X)
+	}
+
+	for x, cell := range cells {
+		idx := index(x, y)
+
+		switch cell {
+		case "□", "○", ".":
+			p.board[idx] = NoHotel
+		case "■":
+			p.board[idx] = UndefinedHotel
+		default:
+			hotel, err := ChainFromInitial(strings.ToUpper(cell))
+			if err != nil || hotel == NoHotel || hotel == UndefinedHotel {
+				return fmt.Errorf("%q at %s isn't a hotel's initial, ■ or □", cell, TileFromBoardIdx(idx).String())
+			}
+			p.board[idx] = hotel
+		}
+	}
+
+	return nil
+}
+
+func (p *positionParser) build() (*Game, error) {
+	if p.numPlayers == 0 {
+		p.numPlayers = util.Max(p.highestPlayer, 2)
+	}
+
+	if p.highestPlayer > p.numPlayers {
+		return nil, fmt.Errorf("player %d is set up, but there are only %d players", p.highestPlayer, p.numPlayers)
+	}
+
+	game := &Game{}
+	game.Rules = p.rules
+	game.Turn = p.turn
+
+	for i := range game.Players {
+		game.Players[i] = Player{Money: game.Rules.StartingMoney}
+		if i < p.numPlayers {
+			game.Players[i].Id = i + 1
+			game.Players[i].DisplayName = p.players[i].name
+			game.Players[i].Agent = p.players[i].agent
+		}
+	}
+
+	for i := range game.Stocks {
+		game.Stocks[i] = TOTAL_STOCKS
+	}
+
+	// computed is kept up to date as the tiles are placed, so it needs to be set up first
+	game.Computed = NewComputed(game)
+
+	for idx, hotel := range p.board {
+		if hotel != NoHotel {
+			game.placeTileOnBoard(TileFromBoardIdx(idx), hotel)
+		}
+	}
+	game.LastPlacedTile = p.last
+
+	if err := p.checkBoard(game); err != nil {
+		return nil, err
+	}
+
+	if err := p.setupPlayers(game); err != nil {
+		return nil, err
+	}
+
+	if err := p.setupPhase(game); err != nil {
+		return nil, err
+	}
+
+	game.SkippedTurnsInARow = p.skippedTurns
+	game.WillEnd = p.willEnd
+	game.IsOver = p.isOver
+	game.EndReason = p.endReason
+
+	game.Computed = NewComputed(game)
+	p.checkOver(game)
+	game.RecomputeHash()
+
+	if err := game.CheckInvariants(); err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+// checkOver
+// ends a game which would end as soon as it was asked for actions, without it having been written as over:
+// the player to place a tile has none and there are none left to draw, or the end was declared on a turn
+// which has finished (the end is declared when a tile is placed, and the game ends with that turn)
+func (p *positionParser) checkOver(game *Game) {
+	if game.IsOver || game.NextActionType != ActionType_PlaceTile {
+		return
+	}
+
+	if !game.hasRemainingTiles() && emptyHand(game.CurrentPlayer()) {
+		game.end("no tiles left")
+		return
+	}
+
+	if _, canEnd := game.CanEnd(); game.WillEnd && canEnd {
+		game.end("a player has declared the game is over")
+	}
+}
+
+// emptyHand
+// whether the player has no tiles at all
+func emptyHand(player *Player) bool {
+	for _, tile := range player.Tiles {
+		if tile != NoTile {
+			return false
+		}
+	}
+	return true
+}
+
+// checkBoard
+// each chain is one group of at least 2 tiles, and no chain touches another chain or a loose tile
+// (except for the tile which started a merger)
+func (p *positionParser) checkBoard(game *Game) error {
+	loose := game.ChainBitboard(UndefinedHotel)
+	if p.phase == ActionType_Merge || p.phase == ActionType_PickHotelToMerge {
+		loose = loose.AndNot(TileBitboard(p.last))
+	}
+
+	for idx, hotel := range HotelChainList {
+		chain := game.ChainBitboard(hotel)
+		if chain.IsEmpty() {
+			continue
+		}
+
+		if _, size := game.ChainAt(chain.Tiles()[0]); size != game.ChainSize[idx] {
+			return fmt.Errorf("%s is split into more than one group", hotel.String())
+		}
+
+		if game.ChainSize[idx] < 2 {
+			return fmt.Errorf("%s only has one tile", hotel.String())
+		}
+
+		if chain.Neighbors().Intersects(loose) {
+			return fmt.Errorf("%s is next to a tile which isn't part of it", hotel.String())
+		}
+
+		for _, other := range HotelChainList {
+			if other != hotel && chain.Neighbors().Intersects(game.ChainBitboard(other)) {
+				return fmt.Errorf("%s is next to %s", hotel.String(), other.String())
+			}
+		}
+	}
+
+	return nil
+}
+
+// setupPlayers
+// gives everyone their money and stocks from the bank, then deals the tiles which aren't on the board
+// or in someone's hand out to anyone who doesn't have any
+func (p *positionParser) setupPlayers(game *Game) error {
+	var used [BOARD_MAX_X * BOARD_MAX_Y]bool
+	for idx, hotel := range p.board {
+		used[idx] = hotel != NoHotel
+	}
+
+	for i := range p.players {
+		pp := &p.players[i]
+		player := &game.Players[i]
+
+		if i >= p.numPlayers {
+			continue
+		}
+
+		if pp.hasMoney {
+			player.Money = pp.money
+		}
+
+		for idx, amount := range pp.stocks {
+			if game.Stocks[idx] < amount {
+				return fmt.Errorf("there aren't enough shares of %s for player %d to have %d", ChainFromIdx(idx).String(), i+1, amount)
+			}
+			game.Stocks[idx] -= amount
+			player.Stocks[idx] = amount
+		}
+
+		for slot, tile := range pp.tiles {
+			if used[tile.Index()] {
+				return fmt.Errorf("player %d has %s, but it's already on the board or in another hand", i+1, tile.String())
+			}
+			used[tile.Index()] = true
+			player.Tiles[slot] = tile
+		}
+	}
+
+	remaining := make([]Tile, 0, len(TileList))
+	for _, tile := range TileList {
+		if !used[tile.Index()] {
+			remaining = append(remaining, tile)
+		}
+	}
+
+	rand.Shuffle(len(remaining), func(i, j int) {
+		remaining[i], remaining[j] = remaining[j], remaining[i]
+	})
+
+	for i := 0; i < p.numPlayers; i++ {
+		if p.players[i].hasTiles {
+			continue
+		}
+
+		for slot := 0; slot < MAX_TILES_IN_HAND && len(remaining) > 0; slot++ {
+			game.Players[i].Tiles[slot] = remaining[0]
+			remaining = remaining[1:]
+		}
+	}
+
+	copy(game.Tiles[:], remaining)
+
+	return nil
+}
+
+// setupPhase
+// sets up whatever the phase needs on top of the board, i.e. the merger state
+func (p *positionParser) setupPhase(game *Game) error {
+	game.NextActionType = p.phase
+
+	lastIsLoose := p.last != NoTile && game.Board[p.last.Index()].Hotel == UndefinedHotel
+	if p.last != NoTile && game.Board[p.last.Index()].Hotel == NoHotel {
+		return fmt.Errorf("the last tile %s isn't on the board", p.last.String())
+	}
+
+	switch p.phase {
+	case ActionType_PickHotelToFound:
+		if !lastIsLoose || game.countUndefinedNeighbors(p.last) == 0 {
+			return fmt.Errorf("to pick a hotel to found, the last tile must be a ■ next to another ■")
+		}
+		game.FoundingHotel = NoHotel
+
+	case ActionType_PickHotelToMerge, ActionType_Merge:
+		chains := game.chainsAround(p.last)
+		if !lastIsLoose || chains.len < 2 {
+			return fmt.Errorf("for a merger, the last tile must be a ■ next to two or more chains")
+		}
+
+		// the largest chains could be safe, but not two of them
+		numSafe := 0
+		for _, hotel := range chains.slice() {
+			if game.ChainSize[hotel.Index()] >= game.Rules.SafeChainSize {
+				numSafe++
+			}
+		}
+		if numSafe > 1 {
+			return fmt.Errorf("%s would merge two safe chains", p.last.String())
+		}
+
+		game.startMerger(chains)
+
+		if p.phase == ActionType_PickHotelToMerge && game.NextActionType != ActionType_PickHotelToMerge {
+			return fmt.Errorf("there's nothing to pick, %s is the largest chain", game.MergerState.AcquiringHotel.String())
+		}
+
+		if p.phase == ActionType_Merge {
+			if game.NextActionType == ActionType_PickHotelToMerge {
+				if p.acquirer == NoHotel {
+					return fmt.Errorf("the largest chains are tied, the acquirer needs to be given")
+				}
+
+				largest, _ := game.getLargestChainsOf(chains)
+				if !largest.contains(p.acquirer) {
+					return fmt.Errorf("%s isn't one of the largest chains in the merger", p.acquirer.String())
+				}
+
+				game.applyPickHotelToMergeAction(Action_PickHotelToMerge{Hotel: p.acquirer})
+			} else if p.acquirer != NoHotel && p.acquirer != game.MergerState.AcquiringHotel {
+				return fmt.Errorf("%s is the largest chain, so it has to be the acquirer", game.MergerState.AcquiringHotel.String())
+			}
+
+			return p.setupMergerProgress(game)
+		}
+	}
+
+	if p.hasMerging || p.mergingPlayer != 0 {
+		return fmt.Errorf("merging and merging player are only for the merge phase")
+	}
+
+	return nil
+}
+
+// setupMergerProgress
+// picks the merger up part way through, when the position says how far it's got
+func (p *positionParser) setupMergerProgress(game *Game) error {
+	if p.mergingPlayer > p.numPlayers {
+		return fmt.Errorf("the merging player %d isn't in the game", p.mergingPlayer)
+	}
+	if p.mergingPlayer != 0 {
+		game.MergerState.MergingPlayerIdx = p.mergingPlayer - 1
+	}
+
+	if !p.hasMerging {
+		return nil
+	}
+
+	var merged hotelSet
+	for _, hotel := range game.MergerState.MergedChains {
+		if hotel != NoHotel {
+			merged.add(hotel)
+		}
+	}
+
+	left := false
+	for idx, remaining := range p.merging {
+		hotel := ChainFromIdx(idx)
+		if remaining > 0 && !merged.contains(hotel) {
+			return fmt.Errorf("%s isn't being merged", hotel.String())
+		}
+		if remaining > p.numPlayers {
+			return fmt.Errorf("%s can't have more players left to merge it than there are players", hotel.String())
+		}
+
+		left = left || remaining > 0
+	}
+
+	if !left {
+		return fmt.Errorf("merging should have at least one chain left to merge")
+	}
+
+	game.MergerState.ChainsToMerge = p.merging
+
+	return nil
+}
+
+// Position
+// describes the game in the format read by ParsePosition, which reads it back as the same game apart from the
+// bank's order (and the rules, which aren't written)
+func (game *Game) Position() string {
+	return game.position(0)
+}
+
+// PositionFor
+// the same as Position, but as the player with the given id sees it: everyone else's tiles are left out, so
+// ParsePosition deals them hands from the tiles which aren't on the board or in the player's hand
+func (game *Game) PositionFor(playerId int) string {
+	return game.position(playerId)
+}
+
+// position
+// the position with only the viewer's tiles, or everyone's when the viewer is 0
+func (game *Game) position(viewer int) string {
+	sb := strings.Builder{}
+
+	fmt.Fprintf(&sb, "players: %d\n", game.numRealPlayers())
+	fmt.Fprintf(&sb, "turn: %d\n", game.Turn)
+	fmt.Fprintf(&sb, "phase: %s\n", strings.ToLower(game.NextActionType.String()))
+
+	if game.LastPlacedTile != NoTile {
+		fmt.Fprintf(&sb, "last: %s\n", game.LastPlacedTile.String())
+	}
+
+	if game.SkippedTurnsInARow > 0 {
+		fmt.Fprintf(&sb, "skipped turns: %d\n", game.SkippedTurnsInARow)
+	}
+	if game.WillEnd {
+		sb.WriteString("will end: true\n")
+	}
+	if game.IsOver {
+		fmt.Fprintf(&sb, "over: %s\n", quote(game.EndReason))
+	}
+
+	if game.NextActionType == ActionType_Merge {
+		fmt.Fprintf(&sb, "acquirer: %s\n", game.MergerState.AcquiringHotel.Initial())
+
+		merging := make([]string, 0, NUM_CHAINS)
+		for idx, remaining := range game.MergerState.ChainsToMerge {
+			if remaining > 0 {
+				merging = append(merging, ChainFromIdx(idx).Initial()+strconv.Itoa(remaining))
+			}
+		}
+		fmt.Fprintf(&sb, "merging: %s\n", strings.Join(merging, " "))
+		fmt.Fprintf(&sb, "merging player: %d\n", game.MergerState.MergingPlayerIdx+1)
+	}
+
+	for i, player := range game.PlayerSlice() {
+		tiles := make([]string, 0, MAX_TILES_IN_HAND)
+		for _, tile := range player.Tiles {
+			if tile != NoTile {
+				tiles = append(tiles, tile.String())
+			}
+		}
+
+		stocks := make([]string, 0, NUM_CHAINS)
+		for idx, amount := range player.Stocks {
+			if amount > 0 {
+				stocks = append(stocks, ChainFromIdx(idx).Initial()+strconv.Itoa(amount))
+			}
+		}
+
+		// a hand which is left out is dealt when parsed, while an empty one stays empty
+		if viewer != 0 && player.Id != viewer {
+			fmt.Fprintf(&sb, "player %d: $%d, stocks %s\n", i+1, player.Money, strings.Join(stocks, " "))
+			continue
+		}
+
+		fmt.Fprintf(&sb, "player %d: $%d, tiles %s, stocks %s\n", i+1, player.Money, strings.Join(tiles, " "), strings.Join(stocks, " "))
+	}
+
+	for i, player := range game.PlayerSlice() {
+		if player.DisplayName != "" {
+			fmt.Fprintf(&sb, "player %d name: %s\n", i+1, quote(player.DisplayName))
+		}
+		if player.Agent != "" {
+			fmt.Fprintf(&sb, "player %d agent: %s\n", i+1, quote(player.Agent))
+		}
+	}
+
+	sb.WriteString(fill(" ", FILL_SIZE))
+	for x := 1; x <= BOARD_MAX_X; x++ {
+		sb.WriteString(fill(strconv.Itoa(x), FILL_SIZE))
+	}
+	sb.WriteString("\n")
+
+	for y := 0; y < BOARD_MAX_Y; y++ {
+		sb.WriteString(fill(chars[y], FILL_SIZE))
+
+		for x := 0; x < BOARD_MAX_X; x++ {
+			switch hotel := game.Board[index(x, y)].Hotel; hotel {
+			case NoHotel:
+				sb.WriteString(fill("□", FILL_SIZE))
+			case UndefinedHotel:
+				sb.WriteString(fill("■", FILL_SIZE))
+			default:
+				sb.WriteString(fill(hotel.Initial(), FILL_SIZE))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// OneLinePosition
+// the same as Position, but on a single line with " / " between the lines, e.g. to send it to another program
+func (game *Game) OneLinePosition() string {
+	return oneLine(game.Position())
+}
+
+// OneLinePositionFor
+// the same as PositionFor, on a single line like OneLinePosition
+func (game *Game) OneLinePositionFor(playerId int) string {
+	return oneLine(game.PositionFor(playerId))
+}
+
+// oneLine
+// the position's lines joined with " / "
+func oneLine(position string) string {
+	lines := strings.Split(strings.TrimSpace(position), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	return strings.Join(lines, " / ")
+}
+
+func parseActionType(s string) (ActionType, error) {
+	for at := ActionType_PlaceTile; at <= ActionType_PurchaseStock; at++ {
+		if strings.EqualFold(strings.TrimSpace(s), at.String()) {
+			return at, nil
+		}
+	}
+
+	return ActionType_PlaceTile, fmt.Errorf("unknown phase %q", s)
+}
+
+// TileFromString
+// the tile with the given name, e.g. "5D" (or "5d")
+func TileFromString(s string) (Tile, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for _, tile := range TileList {
+		if tile.String() == s {
+			return tile, nil
+		}
+	}
+
+	return NoTile, fmt.Errorf("%q isn't a tile", s)
+}
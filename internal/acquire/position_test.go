@@ -0,0 +1,336 @@
+package acquire
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParsePosition(t *testing.T) {
+	game, err := ParsePosition(`
+		players: 3
+		turn: 4
+		phase: purchase stock
+		last: 3A
+		player 1: $5400, tiles 1C 7F, stocks W3 T2
+		player 2: $6000, stocks T1
+		   1  2  3  4  5  6  7  8  9  10 11 12
+		a  W  W  W  □  □  □  □  □  □  □  □  □
+		b  □  □  □  □  ■  □  □  □  □  □  T  T
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if game.numRealPlayers() != 3 || game.Turn != 4 || game.NextActionType != ActionType_PurchaseStock || game.LastPlacedTile != Tile3A {
+		t.Fatal("the header wasn't read")
+	}
+
+	if game.ChainSize[WorldwideHotel.Index()] != 3 || game.ChainSize[TowerHotel.Index()] != 2 || game.Board[Tile5B.Index()].Hotel != UndefinedHotel {
+		t.Fatal("the board wasn't read")
+	}
+
+	p1 := game.Players[0]
+	if p1.Money != 5400 || p1.Tiles[0] != Tile1C || p1.Tiles[1] != Tile7F || p1.Tiles[2] != NoTile {
+		t.Fatal("player 1 wasn't read")
+	}
+
+	if game.Stocks[TowerHotel.Index()] != TOTAL_STOCKS-3 || game.Players[1].Stocks[TowerHotel.Index()] != 1 {
+		t.Fatal("the stocks should be taken from the bank")
+	}
+
+	if game.Players[2].Money != DefaultRules.StartingMoney || game.Players[2].Tiles[MAX_TILES_IN_HAND-1] == NoTile {
+		t.Fatal("player 3 should start with the default money and a hand of tiles")
+	}
+}
+
+func TestParsePositionMerger(t *testing.T) {
+	position := `
+		players: 2
+		phase: merge
+		last: 3A
+		acquirer: T
+		player 1: stocks W2
+		a  W  W  ■  T  T
+	`
+	// rows can't be shorter than the board, so fill the rest in
+	position = strings.Replace(position, "T  T\n", "T  T"+strings.Repeat("  □", BOARD_MAX_X-5)+"\n", 1)
+
+	game, err := ParsePosition(position)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if game.MergerState.AcquiringHotel != TowerHotel || game.MergerState.ChainsToMerge[WorldwideHotel.Index()] != 2 {
+		t.Fatalf("tower should be acquiring worldwide, the merger state was %+v", game.MergerState)
+	}
+
+	// the merger plays out from the position as it would in a game
+	actions := game.GetActions()
+	newGame, _ := game.ApplyAction(actions[0])
+	game = newGame.(*Game)
+	if game.ActivePlayer().Id != 2 {
+		t.Fatal("the second player should be merging next")
+	}
+}
+
+// TestParsePositionMergerProgress
+// a merger can be picked up part way through
+func TestParsePositionMergerProgress(t *testing.T) {
+	position := `
+		players: 3
+		phase: merge
+		last: 3A
+		acquirer: T
+		merging: W1
+		merging player: 3
+		a  W  W  ■  T  T
+	`
+	position = strings.Replace(position, "T  T\n", "T  T"+strings.Repeat("  □", BOARD_MAX_X-5)+"\n", 1)
+
+	game, err := ParsePosition(position)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if game.ActivePlayer().Id != 3 || game.MergerState.ChainsToMerge[WorldwideHotel.Index()] != 1 {
+		t.Fatalf("player 3 should be the last to merge worldwide, the merger state was %+v", game.MergerState)
+	}
+
+	// the last player to merge finishes the merger
+	newGame, _ := game.ApplyAction(game.GetActions()[0])
+	game = newGame.(*Game)
+	if game.NextActionType != ActionType_PurchaseStock || game.Board[Tile3A.Index()].Hotel != TowerHotel {
+		t.Fatal("the merger should be over")
+	}
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	row := func(cells string) string {
+		return cells + strings.Repeat("  □", BOARD_MAX_X-len(strings.Fields(cells))+1)
+	}
+
+	tests := map[string]string{
+		"an unknown header":            "colour: blue",
+		"an unknown phase":             "phase: dancing",
+		"a short row":                  "a  W  W",
+		"an unknown hotel":             row("a  X"),
+		"a chain of one":               row("a  W"),
+		"a split chain":                row("a  W  W  □  W  W"),
+		"touching chains":              row("a  W  W  T  T"),
+		"a chain touching ■":           row("a  W  W  ■"),
+		"a tile twice":                 row("a  ■  ■") + "\nplayer 1: tiles 1A",
+		"too many shares":              "player 1: stocks W20\nplayer 2: stocks W6",
+		"a player too many":            "players: 2\nplayer 3: $100",
+		"a merger without tile":        "phase: merge",
+		"an untied pick":               "phase: pick hotel to merge\nlast: 3A\n" + row("a  W  W  ■  T  T  T"),
+		"a tie with no acquirer":       "phase: merge\nlast: 3A\n" + row("a  W  W  ■  T  T"),
+		"merging outside a merger":     "merging: W1",
+		"merging the acquirer":         "phase: merge\nlast: 3A\nmerging: T1\n" + row("a  W  W  ■  T  T  T"),
+		"a missing merging player":     "players: 2\nphase: merge\nlast: 3A\nmerging player: 3\n" + row("a  W  W  ■  T  T  T"),
+		"an unquoted end reason":       "over: no tiles left",
+		"an unknown player header":     `player 1 colour: "blue"`,
+		"a name for a player too many": "players: 2\n" + `player 3 name: "Carol"`,
+	}
+
+	for name, position := range tests {
+		if _, err := ParsePosition(position); err == nil {
+			t.Errorf("%s should fail to parse", name)
+		}
+	}
+}
+
+// TestPositionRoundTrip
+// writing out a position from a random game and reading it back gives the same game (apart from the bank)
+func TestPositionRoundTrip(t *testing.T) {
+	rand.Seed(7)
+	states, _ := recordRandomGames(10)
+
+	for i := range states {
+		game := &states[i]
+
+		parsed, err := ParsePosition(game.Position())
+		if err != nil {
+			t.Fatalf("state %d: %s\n%s", i, err, game.Position())
+		}
+
+		if parsed.Hash() != game.Hash() || parsed.Board != game.Board || parsed.Turn != game.Turn {
+			t.Fatalf("state %d: the position read back differently\n%s\n%s", i, game.Position(), parsed.Position())
+		}
+
+		if game.NextActionType == ActionType_Merge {
+			merger, parsedMerger := game.MergerState, parsed.MergerState
+			if parsedMerger.ChainsToMerge != merger.ChainsToMerge || parsedMerger.MergingPlayerIdx != merger.MergingPlayerIdx ||
+				parsedMerger.AcquiringHotel != merger.AcquiringHotel {
+				t.Fatalf("state %d: the merger read back differently\n%s\n%s", i, game.Position(), parsed.Position())
+			}
+		}
+
+		oneLine, err := ParsePosition(game.OneLinePosition())
+		if err != nil || oneLine.Position() != game.Position() {
+			t.Fatalf("state %d: the one line position read back differently (%v)\n%s", i, err, game.OneLinePosition())
+		}
+
+		if parsed.Position() != game.Position() {
+			t.Fatalf("state %d: the position read back differently\n%s\n%s", i, game.Position(), parsed.Position())
+		}
+	}
+}
+
+// TestParseFinishedPosition
+// a position which would end as soon as it was played on is read as over, as is one written as over
+func TestParseFinishedPosition(t *testing.T) {
+	full := strings.TrimSpace(strings.Repeat("■  ", BOARD_MAX_X))
+	rows := make([]string, 0, BOARD_MAX_Y)
+	for y := 0; y < BOARD_MAX_Y-1; y++ {
+		rows = append(rows, chars[y]+"  "+full)
+	}
+	rows = append(rows, "i  ■  ■  ■  ■  ■  ■  □  □  □  □  □  □")
+
+	tests := []struct {
+		name     string
+		position string
+		reason   string
+	}{
+		{"no tiles left", "player 1: tiles\nplayer 2: tiles 7I 8I 9I 10I 11I 12I\n" + strings.Join(rows, "\n"), "no tiles left"},
+		{"declared over", "will end: true\n" + row("a", repeat("W", 12)...), "a player has declared the game is over"},
+		{"written as over", `over: "no one had any moves left to play"`, "no one had any moves left to play"},
+	}
+
+	for _, test := range tests {
+		game, err := ParsePosition(test.position)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+
+		if !game.IsTerminal() || game.EndReason != test.reason {
+			t.Errorf("%s: expected the game to be over because %q, was over %v because %q", test.name, test.reason, game.IsTerminal(), game.EndReason)
+		}
+	}
+
+	// declaring the end only ends the game with the turn, and there has to be something to end it for
+	for _, position := range []string{"will end: true\nphase: purchase stock\n" + row("a", repeat("W", 12)...), "will end: true\n" + row("a", "W", "W")} {
+		game, err := ParsePosition(position)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if game.IsTerminal() {
+			t.Errorf("the game shouldn't be over yet\n%s", position)
+		}
+	}
+}
+
+// FuzzPositionRoundTrip
+// a game played a number of random actions in (or to the end), with a named player and the flags which are
+// only sometimes set, reads back from its position as the same game apart from the bank
+func FuzzPositionRoundTrip(f *testing.F) {
+	f.Add(int64(1), uint16(0), "Alice", "MCTS-500", false)
+	f.Add(int64(2), uint16(40), `a / b, "c": d`, "", true)
+	f.Add(int64(3), uint16(65535), "", "engine:./bot --fast", false)
+
+	f.Fuzz(func(t *testing.T, seed int64, numActions uint16, name string, agent string, willEnd bool) {
+		rand.Seed(seed)
+		game := NewGame()
+
+		// the game is always asked for its actions before it's written, so that one which ends then has ended
+		for n := 0; ; n++ {
+			actions := game.GetActions()
+			if game.IsTerminal() || n >= int(numActions) {
+				break
+			}
+
+			newGame, _ := game.ApplyAction(actions[rand.Intn(len(actions))])
+			game = newGame.(*Game)
+		}
+
+		game.Players[0].DisplayName = name
+		game.Players[0].Agent = agent
+
+		// the end is declared when a tile is placed, and the game ends with that turn, so a game is never
+		// waiting for a tile with it declared
+		game.WillEnd = willEnd && game.NextActionType != ActionType_PlaceTile
+
+		parsed, err := ParsePosition(game.Position())
+		if err != nil {
+			t.Fatalf("%s\n%s", err, game.Position())
+		}
+
+		if parsed.Hash() != game.Hash() || parsed.Position() != game.Position() {
+			t.Fatalf("the position read back differently\n%s\n%s", game.Position(), parsed.Position())
+		}
+
+		if parsed.SkippedTurnsInARow != game.SkippedTurnsInARow || parsed.WillEnd != game.WillEnd ||
+			parsed.IsOver != game.IsOver || parsed.EndReason != game.EndReason {
+			t.Fatalf("skipped %d, will end %v, over %v (%q) read back as %d, %v, %v (%q)",
+				game.SkippedTurnsInARow, game.WillEnd, game.IsOver, game.EndReason,
+				parsed.SkippedTurnsInARow, parsed.WillEnd, parsed.IsOver, parsed.EndReason)
+		}
+
+		for i, player := range game.PlayerSlice() {
+			read := parsed.Players[i]
+			if read.DisplayName != player.DisplayName || read.Agent != player.Agent {
+				t.Fatalf("player %d was %q (%q), read back as %q (%q)", player.Id, player.DisplayName, player.Agent, read.DisplayName, read.Agent)
+			}
+		}
+	})
+}
+
+func TestPositionFor(t *testing.T) {
+	rand.Seed(8)
+	states, _ := recordRandomGames(5)
+
+	for i := range states {
+		game := &states[i]
+		viewer := game.ActivePlayer()
+
+		parsed, err := ParsePosition(game.PositionFor(viewer.Id))
+		if err != nil {
+			t.Fatalf("state %d: %s\n%s", i, err, game.PositionFor(viewer.Id))
+		}
+
+		if parsed.Board != game.Board || !sameHand(&parsed.Players[viewer.Id-1], viewer) {
+			t.Fatalf("state %d: the viewer's own position should read back the same\n%s", i, game.PositionFor(viewer.Id))
+		}
+
+		for _, player := range game.PlayerSlice() {
+			if player.Id == viewer.Id {
+				continue
+			}
+
+			// only the tile count is known to the viewer, and the tiles are dealt from the unseen ones
+			for _, tile := range player.Tiles {
+				if tile != NoTile && strings.Contains(game.PositionFor(viewer.Id), " "+tile.String()) {
+					t.Fatalf("state %d: player %d's %s was shown to player %d\n%s", i, player.Id, tile, viewer.Id, game.PositionFor(viewer.Id))
+				}
+			}
+
+			for _, tile := range parsed.Players[player.Id-1].Tiles {
+				if tile != NoTile && (game.Board[tile.Index()].Tile == tile || inHand(viewer, tile)) {
+					t.Fatalf("state %d: player %d was dealt %s, which the viewer can see\n%s", i, player.Id, tile, game.PositionFor(viewer.Id))
+				}
+			}
+		}
+	}
+}
+
+// inHand
+// whether the player holds the tile
+func inHand(player *Player, tile Tile) bool {
+	for _, t := range player.Tiles {
+		if t == tile {
+			return true
+		}
+	}
+	return false
+}
+
+// sameHand
+// whether the players hold the same tiles, in any order
+func sameHand(a, b *Player) bool {
+	for i := range a.Tiles {
+		if !inHand(b, a.Tiles[i]) || !inHand(a, b.Tiles[i]) {
+			return false
+		}
+	}
+	return true
+}
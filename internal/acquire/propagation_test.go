@@ -48,7 +48,7 @@ func TestPropagation(t *testing.T) {
 		t.Fatal("wrong chain size, pre-computation err")
 	}
 
-	Render(game)
+	logGame(t, game)
 
 	// place a new hotel which would merge
 	game.placeTileOnBoard(Tile1D, WorldwideHotel)
@@ -58,7 +58,7 @@ func TestPropagation(t *testing.T) {
 		t.Fatal("wrong chain size, placing tile of a chain should increment chain size")
 	}
 
-	Render(game)
+	logGame(t, game)
 
 	// test the propagation
 	propagateHotelChain(game, PlacedHotel{
@@ -66,7 +66,7 @@ func TestPropagation(t *testing.T) {
 		Tile:  Tile1D,
 	})
 
-	Render(game)
+	logGame(t, game)
 
 	// after propagating into the new chain, the chain size should be the sum of the two, plus 1 for the merging piece
 	if game.ChainSize[WorldwideHotel.Index()] != 25 {
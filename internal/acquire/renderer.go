@@ -2,7 +2,10 @@ package acquire
 
 import (
 	"acquire/internal/util"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -11,36 +14,189 @@ var chars = []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}
 
 const FILL_SIZE = 3
 
-func Clear() {
-	// this clears the console (kinda)
-	fmt.Print("\033[H\033[2J")
+// Renderer
+// draws the game for someone (or something) to look at
+type Renderer interface {
+	Render(w io.Writer, game *Game) error
 }
 
+// PlainRenderer
+// the game as a grid of plain text, with the board, everyone's inventories and the current player's tiles
+type PlainRenderer struct{}
+
+// ANSIRenderer
+// the plain grid with the chains in the palette's colors and the tiles that can be played picked out,
+// redrawn over the whole terminal each time
+type ANSIRenderer struct {
+	Palette Palette
+}
+
+// JSONRenderer
+// the game as the player who is to act sees it (see View), the merging player during a merger,
+// as one line of json per render
+type JSONRenderer struct{}
+
+// NopRenderer
+// draws nothing
+type NopRenderer struct{}
+
+var rendererNames = []string{"plain", "ansi", "json", "none"}
+
+// RendererNames
+// the names ParseRenderer accepts
+func RendererNames() []string {
+	return append([]string(nil), rendererNames...)
+}
+
+// ParseRenderer
+// the renderer with the given name, one of RendererNames. the palette is for the ansi renderer
+func ParseRenderer(name string, palette Palette) (Renderer, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "plain":
+		return PlainRenderer{}, nil
+	case "ansi":
+		return ANSIRenderer{Palette: palette}, nil
+	case "json":
+		return JSONRenderer{}, nil
+	case "none":
+		return NopRenderer{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown renderer '%s', expected %s", name, strings.Join(rendererNames, ", "))
+}
+
+// Render
+// draws the game as plain text to stdout
 func Render(game *Game) {
-	Clear()
-	fmt.Println()
-	fmt.Printf("Acquire | Turn %d | Tiles Left: %d\n", game.Turn, game.NumRemainingTiles())
-	renderPlayers(game)
-	renderPlayerInventories(game)
-	renderBoard(game)
-	renderCurrentPlayer(game)
-	fmt.Println()
+	_ = PlainRenderer{}.Render(os.Stdout, game)
+}
+
+func (PlainRenderer) Render(w io.Writer, game *Game) error {
+	g := grid{w: &errWriter{w: w}, game: game}
+	g.render()
+	return g.w.err
+}
+
+func (r ANSIRenderer) Render(w io.Writer, game *Game) error {
+	g := grid{w: &errWriter{w: w}, game: game, ansi: true, palette: r.Palette}
+
+	// move to the top left and clear the screen
+	g.print("\033[H\033[2J")
+	g.render()
+	return g.w.err
+}
+
+func (JSONRenderer) Render(w io.Writer, game *Game) error {
+	return json.NewEncoder(w).Encode(NewView(game, game.ActivePlayer().Id))
+}
+
+func (NopRenderer) Render(w io.Writer, game *Game) error {
+	return nil
+}
+
+// the ansi styles of the board's spaces which aren't in a chain
+const (
+	styleEmpty          = "38;5;240"
+	styleLegal          = "1;38;5;231;48;5;240"
+	styleUnincorporated = "1;38;5;231;48;5;245"
+)
+
+// markers added to the text of the last tile placed and of safe chains, so they stand out without colors
+const (
+	markLast = "*"
+	markSafe = "\u2022"
+)
+
+// errWriter
+// keeps the first error writing, so the rendering doesn't have to check every write
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
+}
+
+// grid
+// lays out the game as text for the plain and ansi renderers
+type grid struct {
+	w    *errWriter
+	game *Game
+	// whether to draw in color, and which colors
+	ansi    bool
+	palette Palette
+}
+
+func (g *grid) print(a ...any) {
+	fmt.Fprint(g.w, a...)
+}
+
+func (g *grid) printf(format string, a ...any) {
+	fmt.Fprintf(g.w, format, a...)
+}
+
+func (g *grid) println(a ...any) {
+	fmt.Fprintln(g.w, a...)
+}
+
+// style
+// the text in the ansi style, padded to n. plain grids leave out the style
+func (g *grid) style(style string, s string, n int) string {
+	if !g.ansi {
+		return fill(s, n)
+	}
+
+	return "\033[" + style + "m" + s + "\033[0m" + fill("", n-len([]rune(s)))
+}
+
+// chain
+// the text in the chain's color, padded to n
+func (g *grid) chain(hotel Hotel, s string, n int) string {
+	if !g.ansi {
+		return fill(s, n)
+	}
+
+	return g.palette.Paint(hotel, s) + fill("", n-len([]rune(s)))
+}
+
+// isSafe
+// whether the chain is too big to be taken over
+func (g *grid) isSafe(hotel Hotel) bool {
+	return g.game.ChainSize[hotel.Index()] >= g.game.Rules.SafeChainSize
+}
+
+func (g *grid) render() {
+	game := g.game
+
+	g.println()
+	g.printf("Acquire | Turn %d | Tiles Left: %d\n", game.Turn, game.NumRemainingTiles())
+	g.renderPlayers()
+	g.renderPlayerInventories()
+	g.renderBoard()
+	g.renderCurrentPlayer()
+	g.println()
 
 	if game.IsTerminal() {
-		fmt.Println("Game Over, Final Scores")
+		g.println("Game Over, Final Scores")
 
-		for _, p := range game.Players {
-			fmt.Printf("%s: $%d\n", p.Name(), p.NetWorth(game))
+		for _, p := range game.PlayerSlice() {
+			g.printf("%s: $%d\n", p.Description(), p.NetWorth(game))
 		}
-		fmt.Println()
+		g.println()
 
-		fmt.Println("Winner(s)")
+		g.println("Winner(s)")
 		for _, playerId := range game.Winners() {
 			player := game.GetPlayerById(int(playerId))
-			fmt.Printf("%s: $%d ", player.Name(), player.NetWorth(game))
+			g.printf("%s: $%d ", player.Name(), player.NetWorth(game))
 		}
 	}
-
 }
 
 func fill(s string, n int) string {
@@ -60,113 +216,164 @@ func rfill(s string, n int) string {
 	return strings.Repeat(" ", l) + s
 }
 
-func renderCurrentPlayer(game *Game) {
-	player := game.CurrentPlayer()
+func (g *grid) renderCurrentPlayer() {
+	player := g.game.CurrentPlayer()
 
-	fmt.Println()
+	g.println()
 
-	fmt.Printf(fill("Tiles:", 8))
+	g.print(fill("Tiles:", 8))
 	for _, t := range player.Tiles {
-		fmt.Print(fill(fmt.Sprintf("%s ", t.String()), 4))
+		g.print(fill(fmt.Sprintf("%s ", t.String()), 4))
 	}
-	fmt.Println()
+	g.println()
+
+}
 
+// nameWidth
+// the length of the longest player name, for lining up columns
+func nameWidth(game *Game) int {
+	width := 0
+	for _, p := range game.PlayerSlice() {
+		width = util.Max(width, len([]rune(p.Name())))
+	}
+	return width
 }
 
-func renderPlayers(game *Game) {
-	fmt.Printf(fill("Player:", 8))
-	fillSize := 10
+func (g *grid) renderPlayers() {
+	game := g.game
+
+	g.print(fill("Player:", 8))
+	fillSize := util.Max(10, nameWidth(game)+3)
 	for _, p := range game.PlayerSlice() {
 		if p.Id == game.CurrentPlayer().Id {
-			fmt.Print(fill(fmt.Sprintf("[%s] ", p.Name()), fillSize))
+			g.print(fill(fmt.Sprintf("[%s] ", p.Name()), fillSize))
 			continue
 		}
-		fmt.Print(fill(fmt.Sprintf("%s ", p.Name()), fillSize))
+		g.print(fill(fmt.Sprintf("%s ", p.Name()), fillSize))
 	}
-	fmt.Println()
+	g.println()
 }
 
-func renderPlayerInventories(game *Game) {
-	fmt.Println("Inventories:")
-	nameFillSize := 9
+func (g *grid) renderPlayerInventories() {
+	game := g.game
+
+	g.println("Inventories:")
+	nameFillSize := util.Max(9, nameWidth(game)+1)
 	fillSize := 4
 
-	fmt.Print(fill("", nameFillSize))
-	fmt.Print(fill("", nameFillSize))
+	g.print(fill("", nameFillSize))
+	g.print(fill("", nameFillSize))
 	for _, h := range HotelChainList {
-		fmt.Print(fill(strconv.Itoa(game.ChainSize[h.Index()]), fillSize))
+		g.print(fill(strconv.Itoa(game.ChainSize[h.Index()]), fillSize))
 	}
-	fmt.Println()
+	g.println()
 
-	fmt.Print(fill("", nameFillSize))
-	fmt.Print(fill("Money", nameFillSize))
+	g.print(fill("", nameFillSize))
+	g.print(fill("Money", nameFillSize))
 	for _, h := range HotelChainList {
-		fmt.Print(fill(h.Initial(), fillSize))
+		if g.isSafe(h) {
+			g.print(g.chain(h, h.Initial()+markSafe, fillSize))
+			continue
+		}
+		g.print(g.chain(h, h.Initial(), fillSize))
 	}
-	fmt.Print(fill("Net Worth", nameFillSize))
-	println()
+	g.print(fill("Net Worth", nameFillSize))
+	g.println()
 
-	for _, p := range game.Players {
-		fmt.Print(rfill(p.Name()+" ", nameFillSize))
-		fmt.Print(fill(fmt.Sprintf("$%d", p.Money), nameFillSize))
+	for _, p := range game.PlayerSlice() {
+		g.print(rfill(p.Name()+" ", nameFillSize))
+		g.print(fill(fmt.Sprintf("$%d", p.Money), nameFillSize))
 		for h := range HotelChainList {
-			fmt.Print(fill(strconv.Itoa(p.Stocks[h]), fillSize))
+			g.print(fill(strconv.Itoa(p.Stocks[h]), fillSize))
 		}
-		fmt.Print(fill(fmt.Sprintf("$%d", p.NetWorth(game)), nameFillSize))
-		println()
+		g.print(fill(fmt.Sprintf("$%d", p.NetWorth(game)), nameFillSize))
+		g.println()
 	}
 
-	fmt.Print(fill("", nameFillSize))
-	fmt.Print(fill("In Bank", nameFillSize))
+	g.print(fill("", nameFillSize))
+	g.print(fill("In Bank", nameFillSize))
 	for h := range HotelChainList {
-		fmt.Print(fill(strconv.Itoa(game.Stocks[h]), fillSize))
+		g.print(fill(strconv.Itoa(game.Stocks[h]), fillSize))
 	}
 
-	fmt.Println()
-	fmt.Println()
+	g.println()
+	g.println()
 }
 
-func renderBoard(game *Game) {
+func (g *grid) renderBoard() {
+	game := g.game
 
 	for x := 0; x <= BOARD_MAX_X; x++ {
 		if x == 0 {
-			fmt.Print(fill(" ", FILL_SIZE))
+			g.print(fill(" ", FILL_SIZE))
 			continue
 		}
-		fmt.Print(fill(strconv.Itoa(x), FILL_SIZE))
+		g.print(fill(strconv.Itoa(x), FILL_SIZE))
 	}
-	fmt.Println()
+	g.println()
 
-	validPlacementPositions := util.Map(game.Computed.LegalMoves, func(val Tile) util.Point[int] {
-		return val.Pos()
-	})
-	isValidPlacement := func(x int, y int) bool {
-		_, ok := util.IndexOf(validPlacementPositions, util.Point[int]{X: x, Y: y})
-		return ok
-	}
+	legalMoves := game.LegalMovesBitboard()
+	unincorporated := game.ChainBitboard(UndefinedHotel)
 
 	for y := 0; y < BOARD_MAX_Y; y++ {
-		fmt.Print(chars[y] + strings.Repeat(" ", FILL_SIZE-1))
+		g.print(chars[y] + strings.Repeat(" ", FILL_SIZE-1))
 
 		for x := 0; x < BOARD_MAX_X; x++ {
-			placedHotel := game.PlacementAtPos(util.Point[int]{X: x, Y: y})
+			idx := index(x, y)
 
-			if placedHotel.Hotel == NoHotel {
-				if isValidPlacement(x, y) {
-					fmt.Print(fill("\u25CB", FILL_SIZE))
+			if !game.Occupied().Has(idx) {
+				if legalMoves.Has(idx) {
+					g.print(g.style(styleLegal, "\u25CB ", FILL_SIZE))
 				} else {
-					fmt.Print(fill("\u25A1", FILL_SIZE))
+					g.print(g.style(styleEmpty, "\u25A1", FILL_SIZE))
 				}
 				continue
 			}
 
-			if placedHotel.Hotel == UndefinedHotel {
-				fmt.Print(fill("\u25A0", FILL_SIZE))
+			// the color covers the tile and its marker, but not the gap between it and the next space
+			mark := " "
+			if TileFromBoardIdx(idx) == game.LastPlacedTile {
+				mark = markLast
+			}
+
+			if unincorporated.Has(idx) {
+				g.print(g.style(styleUnincorporated, "\u25A0"+mark, FILL_SIZE))
 				continue
 			}
 
-			fmt.Print(fill(placedHotel.Hotel.Initial(), FILL_SIZE))
+			for _, hotel := range HotelChainList {
+				if game.ChainBitboard(hotel).Has(idx) {
+					g.print(g.chain(hotel, hotel.Initial()+mark, FILL_SIZE))
+					break
+				}
+			}
 		}
-		fmt.Println()
+		g.println()
+	}
+
+	g.renderLegend()
+}
+
+// renderLegend
+// the last tile placed and the safe chains, which the board and inventories mark
+func (g *grid) renderLegend() {
+	var notes []string
+
+	if g.game.LastPlacedTile != NoTile {
+		notes = append(notes, fmt.Sprintf("Last Placed: %s%s", g.game.LastPlacedTile.String(), markLast))
+	}
+
+	var safe []string
+	for _, hotel := range HotelChainList {
+		if g.isSafe(hotel) {
+			safe = append(safe, hotel.String())
+		}
+	}
+	if len(safe) > 0 {
+		notes = append(notes, fmt.Sprintf("Safe%s: %s", markSafe, strings.Join(safe, ", ")))
+	}
+
+	if len(notes) > 0 {
+		g.println(strings.Join(notes, " | "))
 	}
 }
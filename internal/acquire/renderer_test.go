@@ -0,0 +1,228 @@
+package acquire
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// logGame
+// the game drawn as plain text into the test's log
+func logGame(t *testing.T, game *Game) {
+	out := strings.Builder{}
+	err := PlainRenderer{}.Render(&out, game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("\n" + out.String())
+}
+
+func renderPosition(t *testing.T, renderer Renderer) string {
+	game, err := ParsePosition(`
+		players: 2
+		last: 2A
+		player 1: tiles 1C 7F
+		a  W  W  □  □  □  □  □  □  □  □  □  □
+		b  □  □  □  □  ■  □  □  □  □  □  □  □
+		i  T  T  T  T  T  T  T  T  T  T  T  T
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Builder{}
+	err = renderer.Render(&out, game)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return out.String()
+}
+
+func TestPlainRenderer(t *testing.T) {
+	out := renderPosition(t, PlainRenderer{})
+
+	if strings.Contains(out, "\033") {
+		t.Error("plain text shouldn't have any escape codes")
+	}
+
+	for _, want := range []string{"Acquire | Turn 0", "a  W  W* □", "b  □  □  □  □  ■", "c  ○  □", "Tiles:  1C  7F", "T\u2022  Net Worth", "Last Placed: 2A* | Safe\u2022: Tower"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in\n%s", want, out)
+		}
+	}
+}
+
+func TestANSIRenderer(t *testing.T) {
+	out := renderPosition(t, ANSIRenderer{})
+
+	if !strings.HasPrefix(out, "\033[H\033[2J") {
+		t.Error("the screen should be cleared first")
+	}
+
+	worldwide := PaletteClassic.Paint(WorldwideHotel, "W ") + " " + PaletteClassic.Paint(WorldwideHotel, "W*") + " "
+	if !strings.Contains(out, "a  "+worldwide+"\033[38;5;240m\u25A1\033[0m") {
+		t.Errorf("worldwide should be painted with the last tile marked, and still line up\n%q", out)
+	}
+
+	if !strings.Contains(out, "c  \033[1;38;5;231;48;5;240m\u25CB \033[0m ") {
+		t.Errorf("legal tiles should stand out\n%q", out)
+	}
+
+	if !strings.Contains(out, PaletteClassic.Paint(TowerHotel, "T\u2022")) {
+		t.Errorf("tower should be marked safe\n%q", out)
+	}
+
+	colorBlind := renderPosition(t, ANSIRenderer{Palette: PaletteColorBlind})
+	if colorBlind == out || !strings.Contains(colorBlind, PaletteColorBlind.Paint(WorldwideHotel, "W ")) {
+		t.Error("the color blind palette should have its own colors")
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	for _, palette := range []Palette{PaletteClassic, PaletteColorBlind} {
+		parsed, err := ParsePalette(strings.ToUpper(palette.String()))
+		if err != nil || parsed != palette {
+			t.Errorf("%s was parsed as %s, %v", palette, parsed, err)
+		}
+
+		if len(paletteColors[palette]) != len(HotelChainList) {
+			t.Errorf("%s should have a color for every chain", palette)
+		}
+	}
+
+	if _, err := ParsePalette("sepia"); err == nil {
+		t.Error("unknown palettes should be refused")
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	out := renderPosition(t, JSONRenderer{})
+
+	if strings.Count(out, "\n") != 1 {
+		t.Errorf("each render should be one line, got %q", out)
+	}
+
+	view := View{}
+	err := json.Unmarshal([]byte(out), &view)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if view.PlayerId != 1 || len(view.Tiles) != 2 || view.Board[0] != "WW.........." {
+		t.Errorf("expected the current player's view, got %+v", view)
+	}
+}
+
+// TestJSONRendererDuringMerger
+// the merging player is the one to act, so it's their view which is rendered
+func TestJSONRendererDuringMerger(t *testing.T) {
+	position := `
+		players: 3
+		phase: merge
+		last: 3A
+		acquirer: T
+		merging: W1
+		merging player: 3
+		a  W  W  ■  T  T
+	`
+	position = strings.Replace(position, "T  T\n", "T  T"+strings.Repeat("  □", BOARD_MAX_X-5)+"\n", 1)
+
+	game, err := ParsePosition(position)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Builder{}
+	err = JSONRenderer{}.Render(&out, game)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view := View{}
+	err = json.Unmarshal([]byte(out.String()), &view)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if view.PlayerId != 3 {
+		t.Errorf("expected the merging player's view, got player %d's", view.PlayerId)
+	}
+}
+
+// TestRendererUnrefreshedHand
+// the tiles picked out as playable follow the hand, even before the game's legal moves are refreshed
+func TestRendererUnrefreshedHand(t *testing.T) {
+	game, err := ParsePosition(`
+		players: 2
+		player 1: tiles 1C 7F
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tile, err := TileFromString("5E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.CurrentPlayer().Tiles[0] = tile
+	game.markLegalMovesDirty()
+
+	out := strings.Builder{}
+	err = PlainRenderer{}.Render(&out, game)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"c  □  □", "e  □  □  □  □  ○"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in\n%s", want, out.String())
+		}
+	}
+}
+
+func TestNopRenderer(t *testing.T) {
+	if out := renderPosition(t, NopRenderer{}); out != "" {
+		t.Errorf("nothing should be drawn, got %q", out)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRenderError(t *testing.T) {
+	for _, renderer := range []Renderer{PlainRenderer{}, ANSIRenderer{}, JSONRenderer{}} {
+		err := renderer.Render(failingWriter{}, NewGame())
+		if err == nil || err.Error() != "disk full" {
+			t.Errorf("%T should return the write error, got %v", renderer, err)
+		}
+	}
+}
+
+func TestParseRenderer(t *testing.T) {
+	for _, name := range RendererNames() {
+		_, err := ParseRenderer(name, PaletteClassic)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	renderer, _ := ParseRenderer(" JSON ", PaletteClassic)
+	if _, ok := renderer.(JSONRenderer); !ok {
+		t.Errorf("names should be matched loosely, got %T", renderer)
+	}
+
+	renderer, _ = ParseRenderer("ansi", PaletteColorBlind)
+	if renderer != (ANSIRenderer{Palette: PaletteColorBlind}) {
+		t.Errorf("the ansi renderer should use the palette, got %+v", renderer)
+	}
+
+	_, err := ParseRenderer("braille", PaletteClassic)
+	if err == nil || !strings.Contains(err.Error(), "plain, ansi, json, none") {
+		t.Errorf("unknown renderers should list the choices, got %v", err)
+	}
+}
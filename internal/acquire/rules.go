@@ -0,0 +1,48 @@
+package acquire
+
+import "fmt"
+
+// Rules
+// the tunable parts of the game, everything else (board size, hand size, number of chains) is fixed
+type Rules struct {
+	// money each player starts the game with
+	StartingMoney int `json:"starting_money"`
+
+	// a chain of this size or larger can no longer be acquired in a merger
+	SafeChainSize int `json:"safe_chain_size"`
+
+	// once a chain reaches this size, a player may declare the game over. declaring isn't wired up yet,
+	// so this only changes what CanEnd reports
+	EndChainSize int `json:"end_chain_size"`
+}
+
+// DefaultRules
+// the rules as written in the box
+var DefaultRules = Rules{
+	StartingMoney: 6000,
+	SafeChainSize: 12,
+	EndChainSize:  41,
+}
+
+// Validate
+// returns an error describing the first rule which doesn't make sense
+func (r Rules) Validate() error {
+	if r.StartingMoney < 0 {
+		return fmt.Errorf("starting money cannot be negative, was %d", r.StartingMoney)
+	}
+
+	if r.SafeChainSize < 2 {
+		return fmt.Errorf("safe chain size must be at least 2, was %d", r.SafeChainSize)
+	}
+
+	if r.EndChainSize < 2 || r.EndChainSize > BOARD_MAX_X*BOARD_MAX_Y {
+		return fmt.Errorf("end chain size must be within 2-%d, was %d", BOARD_MAX_X*BOARD_MAX_Y, r.EndChainSize)
+	}
+
+	// otherwise the game could be declared over while every chain can still be acquired
+	if r.SafeChainSize > r.EndChainSize {
+		return fmt.Errorf("safe chain size can't be larger than the end chain size (%d), was %d", r.EndChainSize, r.SafeChainSize)
+	}
+
+	return nil
+}
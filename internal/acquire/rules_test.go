@@ -0,0 +1,108 @@
+package acquire
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRules(t *testing.T) {
+	if err := DefaultRules.Validate(); err != nil {
+		t.Fatalf("the default rules should be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		rules Rules
+	}{
+		{"negative money", Rules{StartingMoney: -1, SafeChainSize: 11, EndChainSize: 41}},
+		{"tiny safe size", Rules{StartingMoney: 6000, SafeChainSize: 1, EndChainSize: 41}},
+		{"end size off the board", Rules{StartingMoney: 6000, SafeChainSize: 11, EndChainSize: BOARD_MAX_X*BOARD_MAX_Y + 1}},
+		{"safe size over the end size", Rules{StartingMoney: 6000, SafeChainSize: 20, EndChainSize: 15}},
+	}
+
+	for _, test := range tests {
+		if err := test.rules.Validate(); err == nil {
+			t.Errorf("%s should be refused", test.name)
+		}
+	}
+}
+
+// row
+// a board row from its first cells, with the rest left empty
+func row(letter string, cells ...string) string {
+	for len(cells) < BOARD_MAX_X {
+		cells = append(cells, "□")
+	}
+	return letter + " " + strings.Join(cells, " ")
+}
+
+// repeat
+// the cell n times
+func repeat(cell string, n int) []string {
+	cells := make([]string, n)
+	for i := range cells {
+		cells[i] = cell
+	}
+	return cells
+}
+
+// TestCanEnd
+// the game can be declared over once a chain reaches the end size or every chain is safe, by the rules'
+// sizes, but never before there's a chain on the board
+func TestCanEnd(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    Rules
+		board    []string
+		canEnd   bool
+		expected string
+	}{
+		{"empty board", DefaultRules, nil, false, ""},
+		{"loose tiles", Rules{6000, 2, 2}, []string{row("a", "■", "■")}, false, ""},
+		{"unsafe chain", DefaultRules, []string{row("a", "W", "W")}, false, ""},
+		{"all chains safe", Rules{6000, 2, 41}, []string{row("a", "W", "W", "□", "T", "T")}, true, "all chains on the board are safe"},
+		{"one chain unsafe", Rules{6000, 3, 41}, []string{row("a", "W", "W", "W", "□", "T", "T")}, false, ""},
+		{"one under the safe size", DefaultRules, []string{row("a", repeat("W", 11)...)}, false, ""},
+		{"safe at exactly the safe size", DefaultRules, []string{row("a", repeat("W", 12)...)}, true, "all chains on the board are safe"},
+		{"end size reached", Rules{6000, 12, 12}, []string{row("a", repeat("W", 12)...), row("c", "T", "T")}, true, "there is a chain with length 12"},
+	}
+
+	for _, test := range tests {
+		game, err := ParsePositionWithRules(strings.Join(test.board, "\n"), test.rules)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		reason, canEnd := game.CanEnd()
+		if canEnd != test.canEnd || reason != test.expected {
+			t.Errorf("%s: expected %v %q, got %v %q", test.name, test.canEnd, test.expected, canEnd, reason)
+		}
+	}
+}
+
+func TestSafeChainsCantMerge(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		board []string
+		legal bool
+	}{
+		{"two chains of the safe size", DefaultRules, []string{row("a", repeat("W", 12)...), row("c", repeat("T", 12)...)}, false},
+		{"one chain under the safe size", DefaultRules, []string{row("a", repeat("W", 12)...), row("c", repeat("T", 11)...)}, true},
+		{"smaller safe size", Rules{6000, 3, 41}, []string{row("a", "W", "W", "W"), row("c", "T", "T", "T")}, false},
+		{"larger safe size", Rules{6000, 13, 41}, []string{row("a", repeat("W", 12)...), row("c", repeat("T", 12)...)}, true},
+	}
+
+	for _, test := range tests {
+		game, err := ParsePositionWithRules(strings.Join(test.board, "\n"), test.rules)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		// 1B is between the chains, so placing it merges them
+		legal, _ := game.Computed.isLegalToPlace(game, Tile1B)
+		if legal != test.legal {
+			t.Errorf("%s: placing 1B should be legal: %v, was %v", test.name, test.legal, legal)
+		}
+	}
+}
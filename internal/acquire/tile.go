@@ -33,7 +33,7 @@ func randomizedTiles() [108]Tile {
 	tiles := [108]Tile{}
 
 	for i := 0; i < len(TileList); i++ {
-		tiles[i] = Tile(i)
+		tiles[i] = TileList[i]
 	}
 
 	rand.Shuffle(len(tiles), func(i, j int) {
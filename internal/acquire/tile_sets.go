@@ -0,0 +1,118 @@
+package acquire
+
+// tileSets
+// a union-find (disjoint set) over board indexes, where each set is a connected group of placed tiles
+// which all have the same hotel on the board. the root of a set is the index whose Board entry holds the
+// set's hotel, and every member's Board entry is kept in sync with it, so Board stays the public view.
+//
+// each set also keeps its members in a circular list, so that relabelling a set (when a chain is founded,
+// grows or is acquired) only touches the tiles in that set instead of flood filling the board.
+//
+// uint8 is plenty for 108 tiles, and keeps copying a game cheap
+type tileSets struct {
+	parent [BOARD_MAX_X * BOARD_MAX_Y]uint8
+	size   [BOARD_MAX_X * BOARD_MAX_Y]uint8
+	next   [BOARD_MAX_X * BOARD_MAX_Y]uint8
+}
+
+// makeSet
+// puts the index in a set of its own
+func (s *tileSets) makeSet(idx int) {
+	s.parent[idx] = uint8(idx)
+	s.size[idx] = 1
+	s.next[idx] = uint8(idx)
+}
+
+// find
+// the root of the set the index is in.
+// this doesn't compress paths so that it can be used on a game without modifying it,
+// union by size keeps the trees shallow enough without it (at most log2(108) deep)
+func (s *tileSets) find(idx int) int {
+	for int(s.parent[idx]) != idx {
+		idx = int(s.parent[idx])
+	}
+	return idx
+}
+
+// union
+// joins the sets with roots a and b, returning the root of the joined set
+func (s *tileSets) union(a int, b int) int {
+	if a == b {
+		return a
+	}
+
+	// the smaller set goes under the larger one
+	if s.size[a] < s.size[b] {
+		a, b = b, a
+	}
+
+	s.parent[b] = uint8(a)
+	s.size[a] += s.size[b]
+
+	// splice the two circular member lists together
+	s.next[a], s.next[b] = s.next[b], s.next[a]
+
+	return a
+}
+
+// placeInSet
+// adds a newly placed tile to the board's sets, joining it with any neighbors which have the same hotel
+func (game *Game) placeInSet(idx int) {
+	game.tileSets.makeSet(idx)
+
+	hotel := game.Board[idx].Hotel
+	root := idx
+	for _, npt := range TileFromBoardIdx(idx).Pos().OrthogonalNeighbours() {
+		if !isInBounds(npt.X, npt.Y) {
+			continue
+		}
+
+		nIdx := index(npt.X, npt.Y)
+		if game.Board[nIdx].Hotel == hotel {
+			root = game.tileSets.union(root, game.tileSets.find(nIdx))
+		}
+	}
+}
+
+// relabelSet
+// changes the hotel of every tile in the set with the given root, keeping the chain sizes in step
+func (game *Game) relabelSet(root int, hotel Hotel) {
+	oldHotel := game.Board[root].Hotel
+	if oldHotel == hotel {
+		return
+	}
+
+	idx := root
+	for {
+		game.Board[idx].Hotel = hotel
+		game.setBitboardHotel(idx, oldHotel, hotel)
+		game.hash ^= cellKey(idx, oldHotel) ^ cellKey(idx, hotel)
+		idx = int(game.tileSets.next[idx])
+		if idx == root {
+			break
+		}
+	}
+
+	size := int(game.tileSets.size[root])
+	if oldHotel != UndefinedHotel {
+		game.modifyChainSize(oldHotel, -size)
+	}
+	game.modifyChainSize(hotel, size)
+}
+
+// ChainAt
+// the hotel on the tile's position, along with how many tiles are connected to it with that same hotel
+// (for a chain this is the chain size). NoHotel and zero when the tile isn't on the board
+func (game *Game) ChainAt(tile Tile) (Hotel, int) {
+	if tile == NoTile {
+		return NoHotel, 0
+	}
+
+	idx := tile.Index()
+	if game.Board[idx].Hotel == NoHotel {
+		return NoHotel, 0
+	}
+
+	root := game.tileSets.find(idx)
+	return game.Board[root].Hotel, int(game.tileSets.size[root])
+}
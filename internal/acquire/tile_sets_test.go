@@ -0,0 +1,58 @@
+package acquire
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestChainAt(t *testing.T) {
+	game := NewGame()
+
+	if hotel, size := game.ChainAt(Tile1A); hotel != NoHotel || size != 0 {
+		t.Fatal("an empty position should have no hotel")
+	}
+
+	game.placeTileOnBoard(Tile1A, UndefinedHotel)
+	game.placeTileOnBoard(Tile2A, UndefinedHotel)
+	game.placeTileOnBoard(Tile4A, UndefinedHotel)
+
+	if hotel, size := game.ChainAt(Tile2A); hotel != UndefinedHotel || size != 2 {
+		t.Fatalf("1A and 2A should be an undefined group of 2, was %s of %d", hotel.String(), size)
+	}
+
+	// joining the two groups founds a chain out of all of them
+	game.placeTileOnBoard(Tile3A, UndefinedHotel)
+	propagateHotelChain(game, PlacedHotel{Hotel: TowerHotel, Tile: Tile3A})
+
+	if hotel, size := game.ChainAt(Tile1A); hotel != TowerHotel || size != 4 {
+		t.Fatalf("1A-4A should be a tower chain of 4, was %s of %d", hotel.String(), size)
+	}
+
+	if game.ChainSize[TowerHotel.Index()] != 4 {
+		t.Fatal("founding a chain of 4 should make the chain size 4")
+	}
+}
+
+// TestTileSetsMatchBoard
+// plays random games, checking that the sets agree with the board and the chain sizes
+func TestTileSetsMatchBoard(t *testing.T) {
+	rand.Seed(2)
+	states, _ := recordRandomGames(50)
+
+	for i := range states {
+		game := &states[i]
+
+		for idx, placed := range game.Board {
+			hotel, size := game.ChainAt(TileFromBoardIdx(idx))
+			if hotel != placed.Hotel {
+				t.Fatalf("state %d: the set at %s is %s, but the board has %s",
+					i, TileFromBoardIdx(idx).String(), hotel.String(), placed.Hotel.String())
+			}
+
+			if hotel != NoHotel && hotel != UndefinedHotel && size != game.ChainSize[hotel.Index()] {
+				t.Fatalf("state %d: the set at %s has %d tiles, but %s is size %d",
+					i, TileFromBoardIdx(idx).String(), size, hotel.String(), game.ChainSize[hotel.Index()])
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package acquire
+
+import "strings"
+
+// View
+// what one player can see of the game, which is everything but the order of the bank and the other players'
+// tiles. it's written to be marshalled to json, e.g. to send to a player on another machine
+type View struct {
+	// the player the view is for, 0 for a spectator who can't see anyone's tiles
+	PlayerId int `json:"player_id"`
+
+	Turn  int    `json:"turn"`
+	Phase string `json:"phase"`
+	// the player whose turn it is, and the player who has to act next (they differ during a merger)
+	CurrentPlayer int    `json:"current_player"`
+	ActivePlayer  int    `json:"active_player"`
+	TilesLeft     int    `json:"tiles_left"`
+	LastPlaced    string `json:"last_placed,omitempty"`
+
+	// a row for each letter, with a character for each column: a hotel's initial for a tile in that chain,
+	// = for a tile which isn't in a chain yet and . for an empty space
+	Board []string `json:"board"`
+
+	// every chain, in HotelChainList order
+	Chains  []ChainView  `json:"chains"`
+	Players []PlayerView `json:"players"`
+
+	// the player's own tiles
+	Tiles []string `json:"tiles,omitempty"`
+
+	IsOver    bool   `json:"is_over"`
+	EndReason string `json:"end_reason,omitempty"`
+	Rules     Rules  `json:"rules"`
+}
+
+// ChainView
+// a hotel chain as seen by the players
+type ChainView struct {
+	Name    string `json:"name"`
+	Initial string `json:"initial"`
+	Size    int    `json:"size"`
+	// the price of one share, 0 while the chain isn't on the board
+	Price int `json:"price"`
+	// shares left to buy
+	Bank int  `json:"bank"`
+	Safe bool `json:"safe"`
+}
+
+// PlayerView
+// a player as seen by everyone, without their tiles
+type PlayerView struct {
+	Id    int    `json:"id"`
+	Name  string `json:"name"`
+	Agent string `json:"agent,omitempty"`
+	Money int    `json:"money"`
+	// shares held in each chain, in HotelChainList order
+	Stocks   []int `json:"stocks"`
+	NetWorth int   `json:"net_worth"`
+	// how many tiles they have in hand
+	NumTiles int `json:"num_tiles"`
+}
+
+// NewView
+// the game as the player with the given id sees it, pass 0 for a spectator's view
+func NewView(game *Game, playerId int) View {
+	view := View{
+		PlayerId:      playerId,
+		Turn:          game.Turn,
+		Phase:         strings.ToLower(game.NextActionType.String()),
+		CurrentPlayer: game.CurrentPlayer().Id,
+		ActivePlayer:  game.ActivePlayer().Id,
+		TilesLeft:     game.NumRemainingTiles(),
+		Board:         make([]string, BOARD_MAX_Y),
+		IsOver:        game.IsOver,
+		EndReason:     game.EndReason,
+		Rules:         game.Rules,
+	}
+
+	if game.LastPlacedTile != NoTile {
+		view.LastPlaced = game.LastPlacedTile.String()
+	}
+
+	for y := 0; y < BOARD_MAX_Y; y++ {
+		row := strings.Builder{}
+		for x := 0; x < BOARD_MAX_X; x++ {
+			switch hotel := game.Board[index(x, y)].Hotel; hotel {
+			case NoHotel:
+				row.WriteString(".")
+			default:
+				row.WriteString(hotel.Initial())
+			}
+		}
+		view.Board[y] = row.String()
+	}
+
+	for _, hotel := range HotelChainList {
+		size := game.ChainSize[hotel.Index()]
+
+		chain := ChainView{
+			Name:    hotel.String(),
+			Initial: hotel.Initial(),
+			Size:    size,
+			Bank:    game.Stocks[hotel.Index()],
+			Safe:    size >= game.Rules.SafeChainSize,
+		}
+		if size > 0 {
+			chain.Price = hotel.Value(game, 1)
+		}
+
+		view.Chains = append(view.Chains, chain)
+	}
+
+	for _, player := range game.PlayerSlice() {
+		numTiles := 0
+		for _, tile := range player.Tiles {
+			if tile == NoTile {
+				continue
+			}
+
+			numTiles++
+			if player.Id == playerId {
+				view.Tiles = append(view.Tiles, tile.String())
+			}
+		}
+
+		view.Players = append(view.Players, PlayerView{
+			Id:       player.Id,
+			Name:     player.Name(),
+			Agent:    player.Agent,
+			Money:    player.Money,
+			Stocks:   append([]int(nil), player.Stocks[:]...),
+			NetWorth: player.NetWorth(game),
+			NumTiles: numTiles,
+		})
+	}
+
+	return view
+}
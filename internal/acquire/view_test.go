@@ -0,0 +1,51 @@
+package acquire
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	game, err := ParsePosition(`
+		players: 3
+		turn: 1
+		last: 5B
+		player 1: $5400, tiles 1C 7F, stocks W3
+		player 2: tiles 9I
+		a  W  W  □  □  □  □  □  □  □  □  □  □
+		b  □  □  □  □  ■  □  □  □  □  □  □  □
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view := NewView(game, 2)
+	if view.CurrentPlayer != 2 || view.LastPlaced != "5B" || view.Phase != "place tile" {
+		t.Fatalf("the header wasn't viewed, got %+v", view)
+	}
+
+	if view.Board[0] != "WW.........." || view.Board[1] != "....=......." || len(view.Board) != BOARD_MAX_Y {
+		t.Fatalf("unexpected board\n%s", strings.Join(view.Board, "\n"))
+	}
+
+	worldwide := view.Chains[0]
+	if worldwide.Size != 2 || worldwide.Price != 200 || worldwide.Bank != TOTAL_STOCKS-3 || worldwide.Safe {
+		t.Fatalf("unexpected worldwide %+v", worldwide)
+	}
+	if view.Chains[1].Price != 0 {
+		t.Error("chains which aren't on the board shouldn't have a price")
+	}
+
+	if len(view.Tiles) != 1 || view.Tiles[0] != "9I" {
+		t.Fatalf("player 2 should only see their own tile, saw %v", view.Tiles)
+	}
+
+	p1 := view.Players[0]
+	if p1.Money != 5400 || p1.Stocks[0] != 3 || p1.NetWorth != 6000 || p1.NumTiles != 2 {
+		t.Fatalf("unexpected player 1 %+v", p1)
+	}
+
+	if spectator := NewView(game, 0); len(spectator.Tiles) != 0 {
+		t.Error("a spectator shouldn't see any tiles")
+	}
+}
@@ -0,0 +1,139 @@
+package acquire
+
+import "math/rand"
+
+// zobristKeys
+// the random keys which are xor'd together to make a game's hash. they're made from a fixed seed,
+// so hashes are the same between runs and can be saved
+var zobristKeys = newZobristTable(0x5eed)
+
+type zobristTable struct {
+	// index by board position, then hotel (UndefinedHotel and each chain, an empty cell has no key)
+	cells [BOARD_MAX_X * BOARD_MAX_Y][NUM_CHAINS + 1]uint64
+	// index by seat, chain, then number of shares held
+	stocks [MAX_PLAYERS][NUM_CHAINS][TOTAL_STOCKS + 1]uint64
+	// index by seat, mixed with the player's money bucket (see moneyKey)
+	money [MAX_PLAYERS]uint64
+
+	phase        [ActionType_PurchaseStock + 1]uint64
+	activePlayer [MAX_PLAYERS]uint64
+	over         uint64
+}
+
+func newZobristTable(seed int64) *zobristTable {
+	r := rand.New(rand.NewSource(seed))
+	table := &zobristTable{}
+
+	for i := range table.cells {
+		for j := range table.cells[i] {
+			table.cells[i][j] = r.Uint64()
+		}
+	}
+
+	for i := range table.stocks {
+		for j := range table.stocks[i] {
+			for k := range table.stocks[i][j] {
+				table.stocks[i][j][k] = r.Uint64()
+			}
+		}
+	}
+
+	for i := range table.money {
+		table.money[i] = r.Uint64()
+	}
+
+	for i := range table.phase {
+		table.phase[i] = r.Uint64()
+	}
+
+	for i := range table.activePlayer {
+		table.activePlayer[i] = r.Uint64()
+	}
+
+	table.over = r.Uint64()
+
+	return table
+}
+
+func cellKey(idx int, hotel Hotel) uint64 {
+	if hotel == NoHotel {
+		return 0
+	}
+	return zobristKeys.cells[idx][hotel-UndefinedHotel]
+}
+
+func stocksKey(seat int, chainIdx int, shares int) uint64 {
+	return zobristKeys.stocks[seat][chainIdx][shares]
+}
+
+// moneyKey
+// money isn't bounded, so rather than having a key for every amount, the seat's key is mixed with the
+// amount in $100 buckets (every payment in the game is a multiple of $100, so the buckets are exact)
+func moneyKey(seat int, money int) uint64 {
+	// the splitmix64 finalizer
+	x := zobristKeys.money[seat] ^ uint64(money/100)
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Hash
+// a 64 bit zobrist hash of the board, everyone's stocks and money, the phase of the turn and whose action it is.
+// the hands and the bank's tiles aren't part of it. the board, stocks and money are kept up to date as the game
+// changes, so this is cheap enough to call after every action (e.g. for a transposition table)
+func (game *Game) Hash() uint64 {
+	hash := game.hash ^ zobristKeys.phase[game.NextActionType] ^ zobristKeys.activePlayer[game.seatOf(game.ActivePlayer())]
+	if game.IsOver {
+		hash ^= zobristKeys.over
+	}
+	return hash
+}
+
+// computeHash
+// the incrementally updated part of the hash, worked out from scratch
+func (game *Game) computeHash() uint64 {
+	var hash uint64
+
+	for idx, placed := range game.Board {
+		hash ^= cellKey(idx, placed.Hotel)
+	}
+
+	for seat := range game.Players {
+		for chainIdx, shares := range game.Players[seat].Stocks {
+			hash ^= stocksKey(seat, chainIdx, shares)
+		}
+		hash ^= moneyKey(seat, game.Players[seat].Money)
+	}
+
+	return hash
+}
+
+// RecomputeHash
+// recomputes the hash from scratch, needed after modifying the game's fields directly
+func (game *Game) RecomputeHash() {
+	game.hash = game.computeHash()
+}
+
+// seatOf
+// the index of the player in Players
+func (game *Game) seatOf(player *Player) int {
+	for i := range game.Players {
+		if &game.Players[i] == player {
+			return i
+		}
+	}
+	panic("the player isn't in this game")
+}
+
+func (game *Game) stocksChanged(player *Player, chainIdx int, before int) {
+	seat := game.seatOf(player)
+	game.hash ^= stocksKey(seat, chainIdx, before) ^ stocksKey(seat, chainIdx, player.Stocks[chainIdx])
+}
+
+func (game *Game) moneyChanged(player *Player, before int) {
+	seat := game.seatOf(player)
+	game.hash ^= moneyKey(seat, before) ^ moneyKey(seat, player.Money)
+}
@@ -0,0 +1,87 @@
+package acquire
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestIncrementalHash
+// plays random games, checking that the incrementally updated hash is the same as computing it from scratch
+func TestIncrementalHash(t *testing.T) {
+	rand.Seed(4)
+	states, _ := recordRandomGames(50)
+
+	for i := range states {
+		if states[i].hash != states[i].computeHash() {
+			t.Fatalf("state %d: the hash is %x, should be %x", i, states[i].hash, states[i].computeHash())
+		}
+	}
+}
+
+func TestHashDetectsChanges(t *testing.T) {
+	game := NewGame()
+	hash := game.Hash()
+
+	copied := *game
+	if copied.Hash() != hash {
+		t.Fatal("a copy of a game should have the same hash")
+	}
+
+	copied.placeTileOnBoard(Tile1A, UndefinedHotel)
+	if copied.Hash() == hash {
+		t.Fatal("placing a tile should change the hash")
+	}
+
+	copied = *game
+	copied.Players[0].receive(&copied, 100)
+	if copied.Hash() == hash {
+		t.Fatal("a player's money changing should change the hash")
+	}
+
+	copied.Players[0].receive(&copied, -100)
+	if copied.Hash() != hash {
+		t.Fatal("changing a player's money back should change the hash back")
+	}
+
+	copied = *game
+	copied.NextActionType = ActionType_PurchaseStock
+	if copied.Hash() == hash {
+		t.Fatal("moving on to the next phase should change the hash")
+	}
+
+	copied = *game
+	copied.Turn++
+	if copied.Hash() == hash {
+		t.Fatal("the next player's turn should change the hash")
+	}
+}
+
+// TestHashTranspositions
+// buying the same stocks in a different order reaches the same state, so it should have the same hash
+func TestHashTranspositions(t *testing.T) {
+	game := NewGame()
+	game.placeTileOnBoard(Tile1A, UndefinedHotel)
+	game.placeTileOnBoard(Tile2A, UndefinedHotel)
+	propagateHotelChain(game, PlacedHotel{Hotel: TowerHotel, Tile: Tile2A})
+	game.placeTileOnBoard(Tile1C, UndefinedHotel)
+	game.placeTileOnBoard(Tile2C, UndefinedHotel)
+	propagateHotelChain(game, PlacedHotel{Hotel: AmericanHotel, Tile: Tile2C})
+
+	a := *game
+	b := *game
+
+	_ = a.CurrentPlayer().buyStock(&a, TowerHotel, 1)
+	_ = a.CurrentPlayer().buyStock(&a, AmericanHotel, 2)
+
+	_ = b.CurrentPlayer().buyStock(&b, AmericanHotel, 1)
+	_ = b.CurrentPlayer().buyStock(&b, TowerHotel, 1)
+	_ = b.CurrentPlayer().buyStock(&b, AmericanHotel, 1)
+
+	if a.Hash() != b.Hash() {
+		t.Fatal("the same stocks bought in a different order should have the same hash")
+	}
+
+	if a.Hash() == game.Hash() {
+		t.Fatal("buying stocks should change the hash")
+	}
+}
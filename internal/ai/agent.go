@@ -2,9 +2,104 @@ package ai
 
 import (
 	"acquire/internal/acquire"
+	"context"
 	"git.sr.ht/~bonbon/gmcts"
 )
 
+// IAgent
+// the original, minimal agent, which only picks actions. use Adapt to play one as an Agent
 type IAgent interface {
 	SelectAction(game *acquire.Game, actions []gmcts.Action) (gmcts.Action, error)
 }
+
+// ContextAgent
+// an IAgent which can give up part way through picking an action, returning the context's error once it's done.
+// Adapt selects with this when the agent has it, so that it can be stopped (e.g. when it runs out of time)
+type ContextAgent interface {
+	IAgent
+	SelectActionContext(ctx context.Context, game *acquire.Game, actions []gmcts.Action) (gmcts.Action, error)
+}
+
+// Agent
+// a player of the game, which is told about the game from start to finish (see Play)
+type Agent interface {
+	// Name
+	// describes the agent, e.g. "MCTS-500"
+	Name() string
+
+	// OnGameStart
+	// called once before the game begins, with the id of the player the agent is playing as
+	OnGameStart(seat int, rules acquire.Rules)
+
+	// Observe
+	// called after every action in the game, including the agent's own
+	Observe(event Event)
+
+	// SelectAction
+	// picks one of the actions for the agent's player. view is a copy of the game which the agent is free
+	// to search or modify. once the context is done the agent should give up, returning the context's error
+	SelectAction(ctx context.Context, view *acquire.Game, actions []gmcts.Action) (gmcts.Action, error)
+
+	// OnGameEnd
+	// called once the game is over
+	OnGameEnd(result GameResult)
+}
+
+// Event
+// an action which was taken in the game
+type Event struct {
+	PlayerId int
+	Action   gmcts.Action
+	// what happened, from IAction.String
+	Description string
+	// the game after the action
+	Game *acquire.Game
+}
+
+// GameResult
+// how a game ended
+type GameResult struct {
+	Winners   []int
+	EndReason string
+	Game      *acquire.Game
+}
+
+// adaptedAgent
+// an IAgent played as an Agent, see Adapt
+type adaptedAgent struct {
+	agent IAgent
+	name  string
+}
+
+// Adapt
+// plays the IAgent as an Agent with the given name. the IAgent only sees the game when it's asked for an action,
+// it isn't told when the game starts, about each action or how the game ended. unless it's a ContextAgent
+// the context is only checked before it's asked, and it can't be stopped once it has been
+func Adapt(agent IAgent, name string) Agent {
+	return &adaptedAgent{
+		agent: agent,
+		name:  name,
+	}
+}
+
+func (a *adaptedAgent) Name() string {
+	return a.name
+}
+
+func (a *adaptedAgent) OnGameStart(int, acquire.Rules) {}
+
+func (a *adaptedAgent) Observe(Event) {}
+
+func (a *adaptedAgent) SelectAction(ctx context.Context, view *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if agent, ok := a.agent.(ContextAgent); ok {
+		return agent.SelectActionContext(ctx, view, actions)
+	}
+
+	return a.agent.SelectAction(view, actions)
+}
+
+func (a *adaptedAgent) OnGameEnd(GameResult) {}
@@ -2,35 +2,114 @@ package ai
 
 import (
 	"acquire/internal/acquire"
+	"acquire/internal/util"
+	"context"
+	"fmt"
 	"git.sr.ht/~bonbon/gmcts"
+	"os"
+	"path/filepath"
 )
 
 type SmartAgent struct {
 	intelligence int
+
+	// where to write each move's search tree, blank for nowhere. see ExportTrees
+	treeDir   string
+	treeDepth int
+	treeErr   func(error)
+	moves     int
 }
 
+// NewSmartAgent
+// an agent searching this many rounds for each move. it always searches at least one, since there's no
+// best action until something has been searched
 func NewSmartAgent(intelligence int) *SmartAgent {
 	return &SmartAgent{
-		intelligence: intelligence,
+		intelligence: util.Max(intelligence, 1),
 	}
 }
 
-func (agent SmartAgent) SelectAction(game *acquire.Game, _ []gmcts.Action) (gmcts.Action, error) {
+// ExportTrees
+// writes the search tree of every move the agent makes to the directory, down to the given depth,
+// as both graphviz (player1-move001.dot) and json (player1-move001.json). not being able to write a tree
+// doesn't stop the game, the error is passed to onError instead (when it isn't nil)
+func (agent *SmartAgent) ExportTrees(dir string, depth int, onError func(error)) {
+	agent.treeDir = dir
+	agent.treeDepth = depth
+	agent.treeErr = onError
+}
+
+func (agent *SmartAgent) SelectAction(game *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	return agent.SelectActionContext(context.Background(), game, actions)
+}
 
+// SelectActionContext
+// the same as SelectAction, but giving up with the context's error if it's done before the search is.
+// Adapt uses this, so that an agent which has run out of time stops searching
+func (agent *SmartAgent) SelectActionContext(ctx context.Context, game *acquire.Game, _ []gmcts.Action) (gmcts.Action, error) {
 	simGame := game
 	simGame.Sim = true
 
-	mcts := gmcts.NewMCTS(game)
+	tree := NewSearchTree(game, 0)
+
+	// play some n number of game simulations, one at a time so that the search can be stopped
+	for i := 0; i < agent.intelligence; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		tree.SearchRounds(1)
+	}
+
+	return agent.bestAction(tree, game), nil
+}
+
+// SelectActionWithin
+// the same as SelectAction, but searching until the context is done instead of for the agent's number of rounds
+// (though always for at least one round)
+func (agent *SmartAgent) SelectActionWithin(ctx context.Context, game *acquire.Game) gmcts.Action {
+	tree := NewSearchTree(game, 0)
+	tree.SearchRounds(1)
+	tree.Search(ctx)
+
+	return agent.bestAction(tree, game)
+}
+
+// bestAction
+// the best action the search found, once its tree has been written out if the agent is exporting them
+func (agent *SmartAgent) bestAction(tree *SearchTree, game *acquire.Game) gmcts.Action {
+	if agent.treeDir == "" {
+		return tree.BestAction()
+	}
+
+	agent.moves++
 
-	//Spawn a new tree and play some n number game simulations
-	tree := mcts.SpawnTree()
-	tree.SearchRounds(agent.intelligence)
+	err := agent.exportTree(tree, game.ActivePlayer().Id)
+	if err != nil && agent.treeErr != nil {
+		agent.treeErr(fmt.Errorf("could not export the search tree: %w", err))
+	}
+
+	return tree.BestAction()
+}
 
-	//Add the searched tree into the mcts tree collection
-	mcts.AddTree(tree)
+func (agent *SmartAgent) exportTree(tree *SearchTree, playerId int) error {
+	name := filepath.Join(agent.treeDir, fmt.Sprintf("player%d-move%03d", playerId, agent.moves))
 
-	//Get the best action based off of the trees collected from mcts.AddTree()
-	bestAction := mcts.BestAction()
+	dot, err := os.Create(name + ".dot")
+	if err != nil {
+		return err
+	}
+	defer dot.Close()
+
+	err = tree.WriteDOT(dot, agent.treeDepth)
+	if err != nil {
+		return err
+	}
+
+	js, err := os.Create(name + ".json")
+	if err != nil {
+		return err
+	}
+	defer js.Close()
 
-	return bestAction, nil
+	return tree.WriteJSON(js, agent.treeDepth)
 }
@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"context"
+	"errors"
+	"git.sr.ht/~bonbon/gmcts"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSmartAgentMatchesGmcts
+// the agent searches on a SearchTree, whether or not it's exporting it, which should pick the same moves as gmcts
+func TestSmartAgentMatchesGmcts(t *testing.T) {
+	rand.Seed(3)
+	game := acquire.NewGame()
+	dir := t.TempDir()
+
+	for turn := 0; turn < 4; turn++ {
+		actions := game.GetActions()
+
+		// searching can shuffle the bank, so every search starts from the same copy and seed
+		gmctsGame, plainGame, exportedGame := *game, *game, *game
+
+		rand.Seed(int64(turn))
+		mcts := gmcts.NewMCTS(&gmctsGame)
+		tree := mcts.SpawnTree()
+		tree.SearchRounds(40)
+		mcts.AddTree(tree)
+		expected := mcts.BestAction()
+
+		rand.Seed(int64(turn))
+		plain, err := NewSmartAgent(40).SelectAction(&plainGame, actions)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rand.Seed(int64(turn))
+		exporting := NewSmartAgent(40)
+		exporting.ExportTrees(dir, 1, func(err error) { t.Error(err) })
+		action, err := exporting.SelectAction(&exportedGame, actions)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if plain != expected || action != expected {
+			t.Fatalf("turn %d: the agent picked %v, exporting its tree %v, gmcts picked %v", turn, plain, action, expected)
+		}
+
+		newGame, _ := game.ApplyAction(action)
+		game = newGame.(*acquire.Game)
+	}
+
+	for _, name := range []string{"player1-move001.dot", "player1-move001.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("the search tree should have been written, %v", err)
+		}
+	}
+}
+
+func TestSmartAgentSearchesSomething(t *testing.T) {
+	game := acquire.NewGame()
+	action, err := NewSmartAgent(0).SelectAction(game, game.GetActions())
+	if action == nil || err != nil {
+		t.Fatalf("an agent without any rounds should still pick an action, got %v %v", action, err)
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	var errs []error
+	agent := NewSmartAgent(5)
+	agent.ExportTrees(filepath.Join(t.TempDir(), "missing"), 1, func(err error) {
+		errs = append(errs, err)
+	})
+
+	game := acquire.NewGame()
+	action, err := agent.SelectAction(game, game.GetActions())
+	if action == nil || err != nil {
+		t.Fatalf("not being able to write the tree shouldn't stop the agent, got %v %v", action, err)
+	}
+
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "could not export the search tree") {
+		t.Fatalf("the error should have been passed on, got %v", errs)
+	}
+}
+
+func TestSmartAgentCanBeCancelled(t *testing.T) {
+	game := acquire.NewGame()
+	agent := Adapt(NewSmartAgent(1_000_000_000), "MCTS")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	action, err := agent.SelectAction(ctx, game, game.GetActions())
+	if action != nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("the search should give up with the context, got %v %v", action, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("the search should stop once the context is done, it took %s", elapsed)
+	}
+}
+
+func TestTimedOutSearchStops(t *testing.T) {
+	game := acquire.NewGame()
+	actions := game.GetActions()
+
+	var incidents []Incident
+	limited := WithTimeLimit(Adapt(NewSmartAgent(1_000_000_000), "MCTS"), 20*time.Millisecond, FallbackFirst, func(incident Incident) {
+		incidents = append(incidents, incident)
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := limited.SelectAction(context.Background(), game, actions)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the search gives up when it runs out of time, so it's done long before this, ready for the next move
+		if !limited.(*limitedAgent).idle(10 * time.Second) {
+			t.Fatal("the search should have stopped once it ran out of time")
+		}
+	}
+
+	for _, incident := range incidents {
+		if !strings.HasPrefix(incident.Reason, "took longer than") {
+			t.Errorf("the search should have stopped once it ran out of time, got %q", incident.Reason)
+		}
+	}
+	if len(incidents) != 2 {
+		t.Errorf("both moves should have run out of time, got %v", incidents)
+	}
+}
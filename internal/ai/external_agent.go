@@ -0,0 +1,326 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how long past its move time an engine has to answer, and how long it has to start up
+const (
+	externalGrace        = time.Second
+	externalStartTimeout = 10 * time.Second
+)
+
+// errEngineStopped
+// the engine has exited (or closed its input), so it won't be answering any more
+var errEngineStopped = errors.New("the engine stopped")
+
+// ExternalAgent
+// plays a separate engine program over the engine protocol (see the engine package). the engine is started
+// when the game starts and told to quit when it ends. for each move it's sent the position, with only its own
+// tiles (it has to guess everyone else's), and given the move time to think. if it takes too long, crashes or
+// answers with a move that isn't legal, the move is an error, so wrap it with WithTimeLimit to have a fallback
+// played and the incident reported.
+// everything sent to and received from the engine is written to the transcript, as is the engine's stderr
+type ExternalAgent struct {
+	command  []string
+	name     string
+	moveTime time.Duration
+	log      *transcript
+
+	process *exec.Cmd
+	stdin   io.WriteCloser
+	lines   chan string
+
+	// a move was given up on, so the engine's answer to it is still to come
+	behind bool
+	// why the engine can't be used any more, so every move is an error
+	failed error
+}
+
+// transcript
+// the log of an external agent, which the engine's stderr is written to at the same time
+type transcript struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (t *transcript) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.w.Write(p)
+}
+
+func (t *transcript) logf(format string, args ...any) {
+	fmt.Fprintf(t, format+"\n", args...)
+}
+
+// NewExternalAgent
+// an agent which runs the command (the program followed by its arguments) as its engine.
+// the transcript can be nil when it isn't wanted
+func NewExternalAgent(command []string, moveTime time.Duration, transcriptWriter io.Writer) *ExternalAgent {
+	if transcriptWriter == nil {
+		transcriptWriter = io.Discard
+	}
+
+	name := ""
+	if len(command) > 0 {
+		name = filepath.Base(command[0])
+	}
+
+	return &ExternalAgent{
+		command:  command,
+		name:     name,
+		moveTime: moveTime,
+		log:      &transcript{w: transcriptWriter},
+	}
+}
+
+// Name
+// the name the engine gave itself, or the name of its program until it's started
+func (e *ExternalAgent) Name() string {
+	return e.name
+}
+
+func (e *ExternalAgent) OnGameStart(seat int, rules acquire.Rules) {
+	// the agent can play more than one game, but always with a fresh engine
+	e.stop()
+	e.behind = false
+	e.failed = nil
+
+	e.log.logf("! playing as player %d", seat)
+
+	err := e.start(rules)
+	if err != nil {
+		e.fail(err)
+	}
+}
+
+func (e *ExternalAgent) Observe(Event) {}
+
+func (e *ExternalAgent) SelectAction(ctx context.Context, view *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	if len(actions) == 0 {
+		return nil, errors.New("no actions to select")
+	}
+
+	if e.failed != nil {
+		return nil, e.failed
+	}
+
+	if e.behind {
+		_, err := e.await(ctx, "readyok", e.moveTime+externalGrace, "isready")
+		if err != nil {
+			return e.giveUp(ctx, err)
+		}
+		e.behind = false
+	}
+
+	ms := e.moveTime.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+
+	answer, err := e.await(ctx, "bestmove", e.moveTime+externalGrace,
+		"position "+view.OneLinePositionFor(view.ActivePlayer().Id),
+		"go movetime "+strconv.FormatInt(ms, 10),
+	)
+	if err != nil {
+		return e.giveUp(ctx, err)
+	}
+
+	action, err := acquire.FindAction(actions, answer)
+	if err != nil {
+		return e.giveUp(ctx, err)
+	}
+
+	return action, nil
+}
+
+func (e *ExternalAgent) OnGameEnd(GameResult) {
+	e.stop()
+}
+
+// start
+// starts the engine and waits for it to be ready to play a game with the rules
+func (e *ExternalAgent) start(rules acquire.Rules) error {
+	if len(e.command) == 0 {
+		return errors.New("there's no engine to run")
+	}
+
+	e.process = exec.Command(e.command[0], e.command[1:]...)
+	e.process.Stderr = e.log
+
+	var err error
+	e.stdin, err = e.process.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stdout, err := e.process.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	e.log.logf("! starting %s", strings.Join(e.command, " "))
+	if err := e.process.Start(); err != nil {
+		e.process = nil
+		return err
+	}
+
+	lines := make(chan string, 16)
+	e.lines = lines
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	ctx := context.Background()
+	name, err := e.await(ctx, "acquireok", externalStartTimeout, "acquire")
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		e.name = name
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	return e.send("newgame", "rules "+string(data))
+}
+
+// send
+// writes each command to the engine on its own line
+func (e *ExternalAgent) send(commands ...string) error {
+	for _, command := range commands {
+		e.log.logf("> %s", command)
+		if _, err := io.WriteString(e.stdin, command+"\n"); err != nil {
+			return fmt.Errorf("%w: %s", errEngineStopped, err)
+		}
+	}
+
+	return nil
+}
+
+// await
+// sends the commands, then waits for a reply starting with the keyword, returning the rest of it.
+// while waiting for acquireok, the name from an "id name" line is returned instead, and while waiting
+// for readyok any errors left over from the moves which were given up on are skipped
+func (e *ExternalAgent) await(ctx context.Context, keyword string, timeout time.Duration, commands ...string) (string, error) {
+	if err := e.send(commands...); err != nil {
+		return "", err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	reply := ""
+	for {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				return "", errEngineStopped
+			}
+			e.log.logf("< %s", line)
+
+			word, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+			switch {
+			case word == keyword:
+				if keyword == "acquireok" {
+					return reply, nil
+				}
+				return strings.TrimSpace(rest), nil
+			case word == "id" && strings.HasPrefix(rest, "name "):
+				reply = strings.TrimSpace(strings.TrimPrefix(rest, "name "))
+			case word == "error" && keyword != "readyok":
+				// whatever was sent after the command it didn't like will fail too, so catch up next time
+				e.behind = true
+				return "", fmt.Errorf("the engine says %s", rest)
+			}
+
+		case <-timer.C:
+			e.behind = true
+			return "", fmt.Errorf("the engine didn't answer within %s", timeout)
+
+		case <-ctx.Done():
+			e.behind = true
+			return "", ctx.Err()
+		}
+	}
+}
+
+// giveUp
+// gives up on the engine's move, returning why, unless the game has been called off
+func (e *ExternalAgent) giveUp(ctx context.Context, reason error) (gmcts.Action, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if errors.Is(reason, errEngineStopped) {
+		e.fail(reason)
+	} else {
+		e.log.logf("! %s, giving up on the move", reason)
+	}
+
+	return nil, reason
+}
+
+// fail
+// gives up on the engine, the rest of its moves are errors
+func (e *ExternalAgent) fail(reason error) {
+	e.log.logf("! %s, giving up on it for the rest of the game", reason)
+	e.failed = reason
+	e.stop()
+}
+
+// stop
+// asks the engine to quit, and kills it if it won't
+func (e *ExternalAgent) stop() {
+	if e.process == nil {
+		return
+	}
+
+	_ = e.send("quit")
+	e.stdin.Close()
+
+	timer := time.NewTimer(externalGrace)
+	defer timer.Stop()
+
+	for stopped := false; !stopped; {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				stopped = true
+				continue
+			}
+			e.log.logf("< %s", line)
+
+		case <-timer.C:
+			e.log.logf("! the engine didn't quit, killing it")
+			_ = e.process.Process.Kill()
+			for range e.lines {
+			}
+			stopped = true
+		}
+	}
+
+	_ = e.process.Wait()
+	e.process = nil
+}
@@ -0,0 +1,298 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"bufio"
+	"context"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// the test binary runs itself as an engine which misbehaves in the way this is set to
+const fakeEngineEnv = "ACQUIRE_FAKE_ENGINE"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(fakeEngineEnv); mode != "" {
+		runFakeEngine(mode)
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runFakeEngine
+// speaks the engine protocol, answering go with the last legal action, unless it's
+// slow (to answer the first go), illegal (answers with a move that can't be played) or crash (exits on go)
+func runFakeEngine(mode string) {
+	var game *acquire.Game
+	moves := 0
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		command, args, _ := strings.Cut(scanner.Text(), " ")
+
+		switch command {
+		case "acquire":
+			fmt.Println("id name fake-" + mode)
+			fmt.Println("acquireok")
+		case "isready":
+			fmt.Println("readyok")
+		case "position":
+			game, _ = acquire.ParsePosition(args)
+		case "go":
+			moves++
+			actions := game.GetActions()
+
+			switch {
+			case mode == "crash":
+				os.Exit(1)
+			case mode == "illegal":
+				fmt.Println("bestmove 13Z")
+			case mode == "slow" && moves == 1:
+				time.Sleep(externalGrace + 500*time.Millisecond)
+				fallthrough
+			default:
+				fmt.Println("bestmove " + acquire.Notation(actions[len(actions)-1]))
+			}
+		case "quit":
+			return
+		}
+	}
+}
+
+// startFakeEngine
+// an external agent playing the fake engine, and its transcript
+func startFakeEngine(t *testing.T, mode string) (*ExternalAgent, *strings.Builder) {
+	t.Setenv(fakeEngineEnv, mode)
+
+	log := &strings.Builder{}
+	agent := NewExternalAgent([]string{os.Args[0]}, 10*time.Millisecond, log)
+	agent.OnGameStart(1, acquire.DefaultRules)
+	t.Cleanup(func() { agent.OnGameEnd(GameResult{}) })
+
+	return agent, log
+}
+
+// selectTwice
+// asks the agent for its first two moves, which are made from a game with several actions to choose from,
+// returning what it picked (nil where it failed) and its errors
+func selectTwice(agent Agent) ([]gmcts.Action, []gmcts.Action, []error) {
+	rand.Seed(5)
+	game := acquire.NewGame()
+	actions := game.GetActions()
+
+	var picked []gmcts.Action
+	var errs []error
+	for i := 0; i < 2; i++ {
+		action, err := agent.SelectAction(context.Background(), game, actions)
+		picked = append(picked, action)
+		errs = append(errs, err)
+	}
+
+	return actions, picked, errs
+}
+
+func TestExternalAgent(t *testing.T) {
+	agent, log := startFakeEngine(t, "good")
+
+	if agent.Name() != "fake-good" {
+		t.Errorf("expected the engine's name, got %s", agent.Name())
+	}
+
+	actions, picked, errs := selectTwice(agent)
+	for i, action := range picked {
+		if errs[i] != nil || action != actions[len(actions)-1] {
+			t.Errorf("expected the engine's move, got %v (%v)\n%s", action, errs[i], log.String())
+		}
+	}
+
+	if !strings.Contains(log.String(), "> go movetime 10") || strings.Contains(log.String(), "giving up") {
+		t.Errorf("unexpected transcript:\n%s", log.String())
+	}
+}
+
+func TestExternalAgentErrors(t *testing.T) {
+	tests := map[string]struct {
+		// whether the engine's move is played the second time
+		recovers bool
+		logged   string
+	}{
+		"slow":    {recovers: true, logged: "didn't answer within"},
+		"illegal": {recovers: false, logged: "isn't one of the legal actions"},
+		"crash":   {recovers: false, logged: "giving up on it for the rest of the game"},
+	}
+
+	for mode, test := range tests {
+		agent, log := startFakeEngine(t, mode)
+		actions, picked, errs := selectTwice(agent)
+
+		if picked[0] != nil || errs[0] == nil || !strings.Contains(log.String(), errs[0].Error()) {
+			t.Errorf("%s: the first move should have been an error, got %v (%v)", mode, picked[0], errs[0])
+		}
+
+		if recovered := errs[1] == nil && picked[1] == actions[len(actions)-1]; recovered != test.recovers {
+			t.Errorf("%s: expected the engine to recover %t, got %t\n%s", mode, test.recovers, recovered, log.String())
+		}
+
+		if !strings.Contains(log.String(), test.logged) {
+			t.Errorf("%s: expected the transcript to say %q:\n%s", mode, test.logged, log.String())
+		}
+	}
+}
+
+// TestExternalAgentIncidents
+// an engine's failed moves are played for it, and reported, by its time limit
+func TestExternalAgentIncidents(t *testing.T) {
+	agent, _ := startFakeEngine(t, "crash")
+
+	var incidents []Incident
+	limited := WithTimeLimit(agent, time.Minute, FallbackFirst, func(incident Incident) {
+		incidents = append(incidents, incident)
+	})
+
+	actions, picked, errs := selectTwice(limited)
+	for i, action := range picked {
+		if errs[i] != nil || action != actions[0] {
+			t.Errorf("the first action should have been played for the engine, got %v (%v)", action, errs[i])
+		}
+	}
+
+	if len(incidents) != 2 {
+		t.Fatalf("expected both moves to be reported, got %+v", incidents)
+	}
+	for _, incident := range incidents {
+		if incident.Agent != "fake-crash" || !strings.Contains(incident.Reason, "failed: the engine stopped") {
+			t.Errorf("unexpected incident %+v", incident)
+		}
+	}
+}
+
+// TestExternalAgentPlays
+// an engine can play a whole game through Play
+func TestExternalAgentPlays(t *testing.T) {
+	rand.Seed(6)
+	game := acquire.NewGame()
+
+	t.Setenv(fakeEngineEnv, "good")
+	log := &strings.Builder{}
+	agent := NewExternalAgent([]string{os.Args[0]}, 10*time.Millisecond, log)
+
+	agents := map[int]Agent{}
+	for _, p := range game.PlayerSlice() {
+		agents[p.Id] = Adapt(NewStupidAgent(), "random")
+	}
+	agents[1] = agent
+
+	final, err := Play(context.Background(), game, agents, PlayHooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !final.IsTerminal() || strings.Contains(log.String(), "giving up") {
+		t.Errorf("the engine should have played out the game without giving up on a move:\n%s", log.String())
+	}
+
+	if !strings.Contains(log.String(), "> quit\n") {
+		t.Errorf("the engine should be told to quit once the game is over:\n%s", log.String())
+	}
+}
+
+// TestExternalAgentSeesOnlyItsTiles
+// the positions sent to an engine leave out the other players' hands
+func TestExternalAgentSeesOnlyItsTiles(t *testing.T) {
+	rand.Seed(7)
+	game := acquire.NewGame()
+
+	t.Setenv(fakeEngineEnv, "good")
+	log := &strings.Builder{}
+	engine := NewExternalAgent([]string{os.Args[0]}, 10*time.Millisecond, log)
+
+	agents := map[int]Agent{}
+	for _, p := range game.PlayerSlice() {
+		agents[p.Id] = Adapt(NewStupidAgent(), "random")
+	}
+	agents[1] = engine
+
+	// the tiles the engine mustn't see, each time it's asked for a move
+	var hidden [][]string
+	_, err := Play(context.Background(), game, agents, PlayHooks{
+		BeforeSelect: func(game *acquire.Game, agent Agent) {
+			if agent != engine {
+				return
+			}
+
+			var tiles []string
+			for _, p := range game.PlayerSlice() {
+				for _, tile := range p.Tiles {
+					if p.Id != 1 && tile != acquire.NoTile {
+						tiles = append(tiles, tile.String())
+					}
+				}
+			}
+			hidden = append(hidden, tiles)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []string
+	for _, line := range strings.Split(log.String(), "\n") {
+		if position, ok := strings.CutPrefix(line, "> position "); ok {
+			sent = append(sent, position)
+		}
+	}
+	if len(sent) == 0 || len(sent) != len(hidden) {
+		t.Fatalf("expected a position for each of the engine's %d moves, got %d", len(hidden), len(sent))
+	}
+
+	for i, position := range sent {
+		for _, field := range strings.Split(position, " / ") {
+			if strings.HasPrefix(field, "player ") && !strings.HasPrefix(field, "player 1:") && strings.Contains(field, "tiles") {
+				t.Fatalf("another player's hand was sent to the engine: %s", position)
+			}
+		}
+
+		words := strings.Fields(position)
+		for _, tile := range hidden[i] {
+			for _, word := range words {
+				if strings.TrimSuffix(word, ",") == tile {
+					t.Fatalf("the engine was sent %s from another player's hand: %s", tile, position)
+				}
+			}
+		}
+	}
+}
+
+// TestExternalAgentOverrunsItsLimit
+// an engine which takes longer than its time limit is still stopped once the game ends
+// (run with -race to be sure it's never used from two goroutines at once)
+func TestExternalAgentOverrunsItsLimit(t *testing.T) {
+	rand.Seed(5)
+	game := acquire.NewGame()
+
+	t.Setenv(fakeEngineEnv, "slow")
+	log := &strings.Builder{}
+	engine := NewExternalAgent([]string{os.Args[0]}, 10*time.Millisecond, log)
+	limited := WithTimeLimit(engine, 20*time.Millisecond, FallbackFirst, nil)
+
+	limited.OnGameStart(1, game.Rules)
+	actions := game.GetActions()
+	action, err := limited.SelectAction(context.Background(), game, actions)
+	if err != nil || action != actions[0] {
+		t.Fatalf("the move should have fallen back, got %v %v", action, err)
+	}
+	limited.OnGameEnd(GameResult{})
+
+	// the next game gets a fresh engine
+	limited.OnGameStart(1, game.Rules)
+	limited.OnGameEnd(GameResult{})
+	if engine.process != nil || strings.Count(log.String(), "> quit\n") != 2 {
+		t.Errorf("each engine should have been told to quit:\n%s", log.String())
+	}
+}
@@ -75,7 +75,7 @@ func handlePlaceTileActions(game *acquire.Game, actions []gmcts.Action) (gmcts.A
 func handlePurchaseStockActions(game *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
 
 	chains := ""
-	for _, h := range game.Computed.ActiveChains {
+	for _, h := range game.Computed.ActiveChains() {
 		chains += fmt.Sprintf("%s: $%d - [%s]\n", h.String(), h.Value(game, 1), h.Initial())
 	}
 
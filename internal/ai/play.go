@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"context"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+)
+
+// PlayHooks
+// optional callbacks for whoever is running the game, e.g. to render or record it
+type PlayHooks struct {
+	// called before the agent is asked for an action
+	BeforeSelect func(game *acquire.Game, agent Agent)
+	// called after each action, once the agents have observed it
+	OnAction func(event Event)
+}
+
+// Play
+// plays the game to the end with the agents (by player id), starting them, telling every agent about each action
+// and then ending them. returns the game as it was when play stopped, which is only before the end when an agent
+// fails or selects an action which isn't legal (or the context is done)
+func Play(ctx context.Context, game *acquire.Game, agents map[int]Agent, hooks PlayHooks) (*acquire.Game, error) {
+	players := game.PlayerSlice()
+
+	for _, p := range players {
+		agents[p.Id].OnGameStart(p.Id, game.Rules)
+	}
+
+	for !game.IsTerminal() {
+		if err := ctx.Err(); err != nil {
+			return game, err
+		}
+
+		actions := game.GetActions()
+
+		// getting the actions can end the game, when a player has to refresh their hand from an empty bank
+		if game.IsTerminal() {
+			break
+		}
+
+		playerId := game.ActivePlayer().Id
+		agent := agents[playerId]
+
+		if hooks.BeforeSelect != nil {
+			hooks.BeforeSelect(game, agent)
+		}
+
+		view := *game
+		action, err := agent.SelectAction(ctx, &view, actions)
+		if err != nil {
+			return game, fmt.Errorf("%s: %w", agent.Name(), err)
+		}
+
+		iaction, ok := action.(acquire.IAction)
+		if !ok {
+			return game, fmt.Errorf("%s didn't select an action", agent.Name())
+		}
+
+		if !isLegal(action, actions) {
+			return game, fmt.Errorf("%s selected an action which isn't legal: %s", agent.Name(), iaction.String(game))
+		}
+
+		event := Event{
+			PlayerId:    playerId,
+			Action:      action,
+			Description: iaction.String(game),
+		}
+
+		newGame, _ := game.ApplyAction(action)
+		game = newGame.(*acquire.Game)
+		event.Game = game
+
+		for _, p := range players {
+			agents[p.Id].Observe(event)
+		}
+
+		if hooks.OnAction != nil {
+			hooks.OnAction(event)
+		}
+	}
+
+	result := GameResult{
+		EndReason: game.EndReason,
+		Game:      game,
+	}
+	for _, winner := range game.Winners() {
+		result.Winners = append(result.Winners, int(winner))
+	}
+
+	for _, p := range players {
+		agents[p.Id].OnGameEnd(result)
+	}
+
+	return game, nil
+}
+
+// isLegal
+// whether the action is one of the actions
+func isLegal(action gmcts.Action, actions []gmcts.Action) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
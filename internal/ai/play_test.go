@@ -0,0 +1,158 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"context"
+	"errors"
+	"git.sr.ht/~bonbon/gmcts"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// recordingAgent
+// a random agent which keeps track of what it was told
+type recordingAgent struct {
+	Agent
+	seat     int
+	started  int
+	observed []Event
+	result   *GameResult
+}
+
+func newRecordingAgent() *recordingAgent {
+	return &recordingAgent{Agent: Adapt(NewStupidAgent(), "recording")}
+}
+
+func (a *recordingAgent) OnGameStart(seat int, rules acquire.Rules) {
+	a.seat = seat
+	a.started++
+}
+
+func (a *recordingAgent) Observe(event Event) {
+	a.observed = append(a.observed, event)
+}
+
+func (a *recordingAgent) OnGameEnd(result GameResult) {
+	a.result = &result
+}
+
+func TestPlay(t *testing.T) {
+	rand.Seed(4)
+	game := acquire.NewGame()
+
+	recorders := make(map[int]*recordingAgent)
+	agents := make(map[int]Agent)
+	for _, p := range game.PlayerSlice() {
+		recorders[p.Id] = newRecordingAgent()
+		agents[p.Id] = recorders[p.Id]
+	}
+
+	var actions []Event
+	final, err := Play(context.Background(), game, agents, PlayHooks{
+		OnAction: func(event Event) {
+			actions = append(actions, event)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !final.IsTerminal() {
+		t.Fatal("the game should be over")
+	}
+
+	if len(actions) == 0 {
+		t.Fatal("no actions were played")
+	}
+
+	for id, agent := range recorders {
+		if agent.started != 1 || agent.seat != id {
+			t.Errorf("player %d: started %d times as %d", id, agent.started, agent.seat)
+		}
+
+		if len(agent.observed) != len(actions) {
+			t.Errorf("player %d: observed %d of %d actions", id, len(agent.observed), len(actions))
+		}
+
+		if agent.result == nil || agent.result.Game != final || agent.result.EndReason != final.EndReason {
+			t.Errorf("player %d: wasn't told how the game ended", id)
+		} else if len(agent.result.Winners) != len(final.Winners()) {
+			t.Errorf("player %d: told of %d winners, expected %d", id, len(agent.result.Winners), len(final.Winners()))
+		}
+	}
+
+	if actions[len(actions)-1].Game != final {
+		t.Error("the last event should have the final game")
+	}
+}
+
+// TestPlayStopsOnError
+// a cancelled context or a failing agent stops the game
+func TestPlayStopsOnError(t *testing.T) {
+	game := acquire.NewGame()
+
+	agents := make(map[int]Agent)
+	for _, p := range game.PlayerSlice() {
+		agents[p.Id] = Adapt(NewStupidAgent(), "random")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Play(ctx, game, agents, PlayHooks{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the game to be cancelled, got %v", err)
+	}
+
+	agents[game.ActivePlayer().Id] = Adapt(failingAgent{}, "failing")
+	stopped, err := Play(context.Background(), game, agents, PlayHooks{})
+	if err == nil || stopped.IsTerminal() {
+		t.Errorf("expected the failing agent to stop the game, got %v", err)
+	}
+}
+
+// TestPlayRefusesIllegalActions
+// an agent which picks an action it wasn't offered stops the game, rather than it being played
+func TestPlayRefusesIllegalActions(t *testing.T) {
+	game := acquire.NewGame()
+
+	agents := make(map[int]Agent)
+	for _, p := range game.PlayerSlice() {
+		agents[p.Id] = Adapt(NewStupidAgent(), "random")
+	}
+	agents[game.ActivePlayer().Id] = Adapt(scriptedAgent{action: acquire.Action_PickHotelToFound{Hotel: acquire.TowerHotel}}, "cheating")
+
+	stopped, err := Play(context.Background(), game, agents, PlayHooks{})
+	if err == nil || !strings.Contains(err.Error(), "isn't legal") {
+		t.Errorf("expected the illegal action to be refused, got %v", err)
+	}
+	if stopped != game {
+		t.Error("the game shouldn't have been played on")
+	}
+}
+
+type failingAgent struct{}
+
+func (failingAgent) SelectAction(*acquire.Game, []gmcts.Action) (gmcts.Action, error) {
+	return nil, errors.New("no move")
+}
+
+// TestAdaptChecksContext
+// an adapted agent doesn't select once the context is done
+func TestAdaptChecksContext(t *testing.T) {
+	game := acquire.NewGame()
+	agent := Adapt(NewStupidAgent(), "random")
+
+	if agent.Name() != "random" {
+		t.Errorf("expected the name random, got %s", agent.Name())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := agent.SelectAction(ctx, game, game.GetActions())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context's error, got %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"context"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ExplorationConst
+// how strongly the search favours trying less visited actions, the same as gmcts
+const ExplorationConst = math.Sqrt2
+
+// SearchTree
+// a monte carlo tree search over a game, which works the same way as a single gmcts tree
+// (so it picks the same actions for the same seed), but whose nodes can be inspected afterwards.
+// SmartAgent searches with it, whether or not it's exporting its trees
+type SearchTree struct {
+	root       *SearchNode
+	randSource *rand.Rand
+}
+
+// SearchNode
+// a game state in the search tree, reached by taking Action from its parent
+type SearchNode struct {
+	game   *acquire.Game
+	action gmcts.Action
+	tree   *SearchTree
+
+	actions           []gmcts.Action
+	children          []*SearchNode
+	unvisitedChildren []*SearchNode
+	childVisits       []float64
+	actionCount       int
+
+	score  map[gmcts.Player]float64
+	visits int
+
+	// the winners of the node's first playout and what each of them got. gmcts only counts its visit, so it's
+	// kept out of score for the search to pick the same actions, but Score and WinRate include it
+	firstWinners []gmcts.Player
+	firstShare   float64
+}
+
+// NewSearchTree
+// a search from the game, playing out random games with the seed (gmcts uses 0 for its first tree)
+func NewSearchTree(game *acquire.Game, seed int64) *SearchTree {
+	tree := &SearchTree{
+		randSource: rand.New(rand.NewSource(seed)),
+	}
+	tree.root = tree.newNode(game, nil)
+
+	return tree
+}
+
+func (tree *SearchTree) newNode(game *acquire.Game, action gmcts.Action) *SearchNode {
+	return &SearchNode{
+		game:   game,
+		action: action,
+		tree:   tree,
+		score:  make(map[gmcts.Player]float64),
+	}
+}
+
+// SearchRounds
+// plays out this many more games
+func (tree *SearchTree) SearchRounds(rounds int) {
+	for i := 0; i < rounds; i++ {
+		tree.root.runSimulation()
+	}
+}
+
+// Search
+// plays out games until the context is done
+func (tree *SearchTree) Search(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			tree.root.runSimulation()
+		}
+	}
+}
+
+func (tree *SearchTree) Root() *SearchNode {
+	return tree.root
+}
+
+// Player
+// the player the search picks actions for, whose rewards UCT and BestAction go by. this is the game's Player,
+// the player whose turn it is, even while another player is deciding what to do with their shares in a merger
+func (tree *SearchTree) Player() gmcts.Player {
+	return tree.root.game.Player()
+}
+
+// Rounds
+// the number of games played out so far
+func (tree *SearchTree) Rounds() int {
+	return tree.root.visits
+}
+
+// BestAction
+// the action with the best win rate for the player the search plays for (see Player). nil until at least
+// one round has been searched, or when the game is already over
+func (tree *SearchTree) BestAction() gmcts.Action {
+	root := tree.root
+	if root.game.IsTerminal() {
+		return nil
+	}
+
+	var bestAction gmcts.Action
+	bestWinRate := -1.0
+	player := root.game.Player()
+	for i := 0; i < root.actionCount; i++ {
+		winRate := root.children[i].score[player] / root.childVisits[i]
+		if winRate > bestWinRate {
+			bestAction = root.actions[i]
+			bestWinRate = winRate
+		}
+	}
+
+	return bestAction
+}
+
+// runSimulation
+// one round of the search: walk down the tree picking children by UCT, expand the first unvisited child
+// and play a random game out from it, then add the result to every node on the way back up
+func (n *SearchNode) runSimulation() ([]gmcts.Player, float64) {
+	var selectedChildIndex int
+	var winners []gmcts.Player
+	var scoreToAdd float64
+	var terminalState bool
+
+	if n.actionCount == 0 {
+		terminalState = n.game.IsTerminal()
+		if !terminalState {
+			n.expand()
+		}
+	}
+
+	if terminalState {
+		winners = n.simulate()
+		scoreToAdd = 1.0 / float64(len(winners))
+	} else if len(n.unvisitedChildren) > 0 {
+		// like gmcts, the child counts the visit but its own score only starts with its second (see firstWinners)
+		selectedChildIndex = n.actionCount - len(n.unvisitedChildren)
+		child := n.children[selectedChildIndex]
+		child.visits++
+		n.unvisitedChildren = n.unvisitedChildren[1:]
+
+		winners = child.simulate()
+		scoreToAdd = 1.0 / float64(len(winners))
+		child.firstWinners, child.firstShare = winners, scoreToAdd
+	} else {
+		maxScore := -1.0
+		thisPlayer := n.game.Player()
+		for i := 0; i < n.actionCount; i++ {
+			score := n.uct(i, thisPlayer)
+			if score > maxScore {
+				maxScore = score
+				selectedChildIndex = i
+			}
+		}
+		winners, scoreToAdd = n.children[selectedChildIndex].runSimulation()
+	}
+
+	n.visits++
+	if n.actionCount != 0 {
+		n.childVisits[selectedChildIndex]++
+	}
+
+	for _, p := range winners {
+		n.score[p] += scoreToAdd
+	}
+
+	return winners, scoreToAdd
+}
+
+func (n *SearchNode) uct(i int, p gmcts.Player) float64 {
+	exploit := n.children[i].score[p] / float64(n.children[i].visits)
+
+	explore := math.Sqrt(
+		math.Log(float64(n.visits)) / n.childVisits[i],
+	)
+
+	return exploit + ExplorationConst*explore
+}
+
+func (n *SearchNode) expand() {
+	n.actions = n.game.GetActions()
+	n.actionCount = len(n.actions)
+	n.unvisitedChildren = make([]*SearchNode, n.actionCount)
+	n.children = n.unvisitedChildren
+	n.childVisits = make([]float64, n.actionCount)
+
+	for i, a := range n.actions {
+		newGame, err := n.game.ApplyAction(a)
+		if err != nil {
+			panic(fmt.Sprintf("the game returned an error when exploring the tree: %s", err))
+		}
+
+		n.unvisitedChildren[i] = n.tree.newNode(newGame.(*acquire.Game), a)
+	}
+}
+
+// simulate
+// plays random actions until the game is over, returning the winners
+func (n *SearchNode) simulate() []gmcts.Player {
+	var game gmcts.Game = n.game
+	for !game.IsTerminal() {
+		actions := game.GetActions()
+		if len(actions) == 0 {
+			panic("the game isn't over, but there are no actions")
+		}
+
+		var err error
+		game, err = game.ApplyAction(actions[n.tree.randSource.Intn(len(actions))])
+		if err != nil {
+			panic(fmt.Sprintf("the game returned an error while searching the tree: %s", err))
+		}
+	}
+
+	return game.Winners()
+}
+
+// Game
+// the state of the game at this node
+func (n *SearchNode) Game() *acquire.Game {
+	return n.game
+}
+
+// Action
+// the action taken from the parent to get here, nil for the root
+func (n *SearchNode) Action() gmcts.Action {
+	return n.action
+}
+
+// Visits
+// the number of played out games which went through this node
+func (n *SearchNode) Visits() int {
+	return n.visits
+}
+
+// Score
+// the total reward the player got from the games played out through this node,
+// each game is worth 1 split between its winners
+func (n *SearchNode) Score(player gmcts.Player) float64 {
+	score := n.score[player]
+	for _, p := range n.firstWinners {
+		if p == player {
+			score += n.firstShare
+		}
+	}
+	return score
+}
+
+// WinRate
+// the player's average reward from the games played out through this node, an estimate of their chance
+// of winning from here. zero when the node hasn't been visited
+func (n *SearchNode) WinRate(player gmcts.Player) float64 {
+	if n.visits == 0 {
+		return 0
+	}
+	return n.Score(player) / float64(n.visits)
+}
+
+// Children
+// the nodes for each action available here, empty until the node has been expanded
+func (n *SearchNode) Children() []*SearchNode {
+	return n.children
+}
+
+// RankedChildren
+// the visited children, most visited first (ties broken by win rate for the player to move)
+func (n *SearchNode) RankedChildren() []*SearchNode {
+	player := n.game.Player()
+
+	ranked := make([]*SearchNode, 0, len(n.children))
+	for _, child := range n.children {
+		if child.visits > 0 {
+			ranked = append(ranked, child)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].visits != ranked[j].visits {
+			return ranked[i].visits > ranked[j].visits
+		}
+		return ranked[i].WinRate(player) > ranked[j].WinRate(player)
+	})
+
+	return ranked
+}
@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"encoding/json"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// exportedNode
+// the json layout of a node in an exported search tree
+type exportedNode struct {
+	// the action taken to get here, blank for the root
+	Action string `json:"action,omitempty"`
+	// the id of the player who took the action, zero for the root
+	Player int `json:"player,omitempty"`
+	Visits int `json:"visits"`
+	// the total reward for each player (by id) from the games played through here
+	Scores   map[string]float64 `json:"scores"`
+	Children []*exportedNode    `json:"children,omitempty"`
+}
+
+// export
+// the node and its visited children down to depth levels below it, most visited first
+func (n *SearchNode) export(parent *acquire.Game, depth int) *exportedNode {
+	node := &exportedNode{
+		Visits: n.visits,
+		Scores: make(map[string]float64),
+	}
+
+	if parent != nil {
+		node.Action = n.action.(acquire.IAction).String(parent)
+		node.Player = parent.ActivePlayer().Id
+	}
+
+	for _, p := range n.game.PlayerSlice() {
+		node.Scores[strconv.Itoa(p.Id)] = n.Score(gmcts.Player(p.Id))
+	}
+
+	if depth > 0 {
+		for _, child := range n.RankedChildren() {
+			node.Children = append(node.Children, child.export(n.game, depth-1))
+		}
+	}
+
+	return node
+}
+
+// WriteJSON
+// writes the tree down to the given depth (0 is only the root) as json
+func (tree *SearchTree) WriteJSON(w io.Writer, depth int) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(tree.root.export(nil, depth))
+}
+
+// WriteDOT
+// writes the tree down to the given depth (0 is only the root) as a graphviz digraph,
+// e.g. to render it with 'dot -Tsvg tree.dot > tree.svg'. each node is labelled with the action
+// that led to it, its visits, and the win rate of the player who took that action
+func (tree *SearchTree) WriteDOT(w io.Writer, depth int) error {
+	sb := strings.Builder{}
+	sb.WriteString("digraph search {\n")
+	sb.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	root := tree.root.export(nil, depth)
+	fmt.Fprintf(&sb, "\tn0 [label=%s];\n", strconv.Quote(fmt.Sprintf("root\nvisits: %d", root.Visits)))
+
+	next := 1
+	var writeChildren func(id int, node *exportedNode)
+	writeChildren = func(id int, node *exportedNode) {
+		for _, child := range node.Children {
+			childId := next
+			next++
+
+			winRate := 0.0
+			if child.Visits > 0 {
+				winRate = child.Scores[strconv.Itoa(child.Player)] / float64(child.Visits)
+			}
+
+			label := fmt.Sprintf("%s\nvisits: %d\nwin rate: %.1f%%", child.Action, child.Visits, 100*winRate)
+			fmt.Fprintf(&sb, "\tn%d [label=%s];\n", childId, strconv.Quote(label))
+			fmt.Fprintf(&sb, "\tn%d -> n%d;\n", id, childId)
+
+			writeChildren(childId, child)
+		}
+	}
+	writeChildren(0, root)
+
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func searchedTree() *SearchTree {
+	rand.Seed(3)
+	tree := NewSearchTree(acquire.NewGame(), 0)
+	tree.SearchRounds(100)
+	return tree
+}
+
+func TestWriteJSON(t *testing.T) {
+	tree := searchedTree()
+
+	out := bytes.Buffer{}
+	err := tree.WriteJSON(&out, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root exportedNode
+	err = json.Unmarshal(out.Bytes(), &root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if root.Visits != 100 || len(root.Children) != len(tree.Root().RankedChildren()) {
+		t.Fatalf("the root should have 100 visits and all its visited children, had %d and %d", root.Visits, len(root.Children))
+	}
+
+	child := root.Children[0]
+	if !strings.Contains(child.Action, "places tile") || child.Player != 1 {
+		t.Fatalf("the first move should be player 1 placing a tile, was '%s' by %d", child.Action, child.Player)
+	}
+
+	if len(child.Children) != 0 {
+		t.Fatal("nothing below the depth should be written")
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	tree := searchedTree()
+
+	out := bytes.Buffer{}
+	err := tree.WriteDOT(&out, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dot := out.String()
+	if !strings.HasPrefix(dot, "digraph search {") || !strings.HasSuffix(dot, "}\n") {
+		t.Fatalf("not a digraph:\n%s", dot)
+	}
+
+	// one edge for every visited node in the first two levels
+	edges := 0
+	for _, child := range tree.Root().RankedChildren() {
+		edges += 1 + len(child.RankedChildren())
+	}
+
+	if strings.Count(dot, " -> ") != edges {
+		t.Fatalf("expected %d edges, got %d", edges, strings.Count(dot, " -> "))
+	}
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"git.sr.ht/~bonbon/gmcts"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestSearchMatchesGmcts
+// the search tree should pick the same actions as a gmcts tree with the same seed
+func TestSearchMatchesGmcts(t *testing.T) {
+	rand.Seed(1)
+	game := acquire.NewGame()
+
+	for turn := 0; turn < 12 && !game.IsTerminal(); turn++ {
+		actions := game.GetActions()
+		if game.IsTerminal() {
+			break
+		}
+
+		// searching can shuffle the bank, so both searches start from the same copy and seed
+		gmctsGame, searchGame := *game, *game
+
+		rand.Seed(int64(turn))
+		mcts := gmcts.NewMCTS(&gmctsGame)
+		gmctsTree := mcts.SpawnTree()
+		gmctsTree.SearchRounds(50)
+		mcts.AddTree(gmctsTree)
+		expected := mcts.BestAction()
+
+		rand.Seed(int64(turn))
+		tree := NewSearchTree(&searchGame, 0)
+		tree.SearchRounds(50)
+
+		if tree.BestAction() != expected {
+			t.Fatalf("turn %d: the search picked %v, gmcts picked %v", turn, tree.BestAction(), expected)
+		}
+
+		if tree.Rounds() != gmctsTree.Rounds() {
+			t.Fatalf("turn %d: searched %d rounds, gmcts searched %d", turn, tree.Rounds(), gmctsTree.Rounds())
+		}
+
+		newGame, _ := game.ApplyAction(actions[rand.Intn(len(actions))])
+		game = newGame.(*acquire.Game)
+	}
+}
+
+func TestRankedChildren(t *testing.T) {
+	rand.Seed(2)
+	tree := NewSearchTree(acquire.NewGame(), 0)
+	tree.SearchRounds(200)
+
+	ranked := tree.Root().RankedChildren()
+	if len(ranked) == 0 {
+		t.Fatal("the root should have visited children")
+	}
+
+	total := 0
+	for i, child := range ranked {
+		total += child.Visits()
+		if i > 0 && child.Visits() > ranked[i-1].Visits() {
+			t.Fatal("children should be ranked by visits")
+		}
+	}
+
+	if total != tree.Rounds() {
+		t.Fatalf("the children have %d visits between them, but %d rounds were searched", total, tree.Rounds())
+	}
+}
+
+// TestWinRatesAddUp
+// every game played out through a node is shared out between its winners, including the node's first
+func TestWinRatesAddUp(t *testing.T) {
+	rand.Seed(5)
+	tree := NewSearchTree(acquire.NewGame(), 0)
+	tree.SearchRounds(300)
+
+	players := tree.Root().Game().PlayerSlice()
+
+	var check func(n *SearchNode, depth int)
+	check = func(n *SearchNode, depth int) {
+		total := 0.0
+		for _, p := range players {
+			total += n.WinRate(gmcts.Player(p.Id))
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Fatalf("the win rates of a node with %d visits add up to %f", n.Visits(), total)
+		}
+
+		if depth > 0 {
+			for _, child := range n.RankedChildren() {
+				check(child, depth-1)
+			}
+		}
+	}
+	check(tree.Root(), 2)
+}
@@ -0,0 +1,135 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SeatType
+// what plays a seat
+type SeatType int
+
+const (
+	// someone at the terminal
+	SeatHuman SeatType = iota
+	// a player who joins a server
+	SeatRemote
+	SeatRandom
+	SeatMCTS
+	// an external engine program, see ExternalAgent
+	SeatEngine
+)
+
+// the strength of mcts seats which don't give one
+const DefaultStrength = 500
+
+// Seat
+// what plays a seat, described as human, remote, random, mcts[:strength] or engine:command (see ParseSeat)
+type Seat struct {
+	Type SeatType
+	// the number of rounds an mcts seat searches for each move
+	Strength int
+	// the command line an engine seat runs, the program followed by its arguments
+	Command string
+}
+
+// ParseSeat
+// reads a seat such as 'human', 'mcts:500' or 'engine:./bot --fast'
+func ParseSeat(s string) (Seat, error) {
+	name, param, hasParam := strings.Cut(s, ":")
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	switch name {
+	case "human":
+		return simpleSeat(SeatHuman, name, hasParam)
+	case "remote":
+		return simpleSeat(SeatRemote, name, hasParam)
+	case "random":
+		return simpleSeat(SeatRandom, name, hasParam)
+
+	case "mcts", "ai":
+		if !hasParam {
+			return Seat{Type: SeatMCTS, Strength: DefaultStrength}, nil
+		}
+
+		strength, err := strconv.Atoi(strings.TrimSpace(param))
+		if err != nil || strength < 1 {
+			return Seat{}, fmt.Errorf("ai strength must be a positive number of search rounds, was '%s'", param)
+		}
+		return Seat{Type: SeatMCTS, Strength: strength}, nil
+
+	case "engine":
+		command := strings.TrimSpace(param)
+		if command == "" {
+			return Seat{}, errors.New("engine players need the command to run, e.g. engine:./bot")
+		}
+		return Seat{Type: SeatEngine, Command: command}, nil
+
+	default:
+		return Seat{}, fmt.Errorf("unknown agent type '%s', expected human, remote, random, mcts or engine", name)
+	}
+}
+
+// simpleSeat
+// a seat of a type which doesn't take a parameter
+func simpleSeat(seatType SeatType, name string, hasParam bool) (Seat, error) {
+	if hasParam {
+		return Seat{}, fmt.Errorf("%s players don't take a strength", name)
+	}
+	return Seat{Type: seatType}, nil
+}
+
+// String
+// the seat as ParseSeat reads it
+func (s Seat) String() string {
+	switch s.Type {
+	case SeatHuman:
+		return "human"
+	case SeatRemote:
+		return "remote"
+	case SeatRandom:
+		return "random"
+	case SeatEngine:
+		return "engine:" + s.Command
+	default:
+		return "mcts:" + strconv.Itoa(s.Strength)
+	}
+}
+
+// Description
+// a human friendly version of String, e.g. "MCTS-500", which the seat's agent is named
+func (s Seat) Description() string {
+	switch s.Type {
+	case SeatHuman:
+		return "Human"
+	case SeatRemote:
+		return "Remote"
+	case SeatRandom:
+		return "Random"
+	case SeatEngine:
+		return "Engine " + filepath.Base(strings.Fields(s.Command)[0])
+	default:
+		return "MCTS-" + strconv.Itoa(s.Strength)
+	}
+}
+
+// NewAgent
+// the agent for a random, mcts or engine seat, nil for human and remote seats which are played from elsewhere.
+// engines get engineTime for each move, and write their transcripts to log (which can be nil)
+func (s Seat) NewAgent(engineTime time.Duration, log io.Writer) Agent {
+	switch s.Type {
+	case SeatRandom:
+		return Adapt(NewStupidAgent(), s.Description())
+	case SeatMCTS:
+		return Adapt(NewSmartAgent(s.Strength), s.Description())
+	case SeatEngine:
+		return NewExternalAgent(strings.Fields(s.Command), engineTime, log)
+	default:
+		return nil
+	}
+}
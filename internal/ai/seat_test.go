@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSeat(t *testing.T) {
+	tests := map[string]Seat{
+		"human":               {Type: SeatHuman},
+		" Remote":             {Type: SeatRemote},
+		"random":              {Type: SeatRandom},
+		"mcts":                {Type: SeatMCTS, Strength: DefaultStrength},
+		"AI:200":              {Type: SeatMCTS, Strength: 200},
+		"engine:./bot --fast": {Type: SeatEngine, Command: "./bot --fast"},
+	}
+
+	for s, expected := range tests {
+		seat, err := ParseSeat(s)
+		if err != nil || seat != expected {
+			t.Errorf("%q: expected %+v, got %+v %v", s, expected, seat, err)
+			continue
+		}
+
+		// reading a seat back gives the same seat
+		if again, err := ParseSeat(seat.String()); err != nil || again != seat {
+			t.Errorf("%q: read back from %q as %+v %v", s, seat.String(), again, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"wizard":    "unknown agent type 'wizard'",
+		"mcts:-5":   "positive number of search rounds",
+		"mcts:lots": "positive number of search rounds",
+		"human:3":   "don't take a strength",
+		"remote:1":  "don't take a strength",
+		"engine: ":  "need the command to run",
+	}
+
+	for s, expected := range invalid {
+		if _, err := ParseSeat(s); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%q: expected an error containing %q, got %v", s, expected, err)
+		}
+	}
+}
+
+func TestSeatAgents(t *testing.T) {
+	tests := []struct {
+		seat        string
+		description string
+		// whether the seat is played on this machine by an agent NewAgent makes
+		hasAgent bool
+	}{
+		{"human", "Human", false},
+		{"remote", "Remote", false},
+		{"random", "Random", true},
+		{"mcts:20", "MCTS-20", true},
+		{"engine:./bots/greedy --quiet", "Engine greedy", true},
+	}
+
+	for _, test := range tests {
+		seat, err := ParseSeat(test.seat)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if seat.Description() != test.description {
+			t.Errorf("%s: expected to be described as %q, got %q", test.seat, test.description, seat.Description())
+		}
+
+		agent := seat.NewAgent(0, nil)
+		if (agent != nil) != test.hasAgent {
+			t.Errorf("%s: expected an agent %t, got %v", test.seat, test.hasAgent, agent)
+		}
+		if agent != nil && seat.Type != SeatEngine && agent.Name() != test.description {
+			t.Errorf("%s: the agent should be named %q, was %q", test.seat, test.description, agent.Name())
+		}
+	}
+}
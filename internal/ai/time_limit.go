@@ -0,0 +1,249 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"context"
+	"errors"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Fallback
+// how to pick an action for an agent which didn't pick one itself
+type Fallback int
+
+const (
+	// the first action, which never buys anything or gives up any shares
+	FallbackFirst Fallback = iota
+	FallbackRandom
+	// a quick search, much weaker than a real one but better than chance
+	FallbackHeuristic
+)
+
+// the number of rounds the heuristic fallback searches for
+const heuristicRounds = 50
+
+var fallbackNames = []string{"first", "random", "heuristic"}
+
+func (f Fallback) String() string {
+	return fallbackNames[f]
+}
+
+// ParseFallback
+// the fallback with the given name, as from String
+func ParseFallback(s string) (Fallback, error) {
+	for f, name := range fallbackNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return Fallback(f), nil
+		}
+	}
+
+	return FallbackFirst, fmt.Errorf("unknown fallback '%s', expected %s", s, strings.Join(fallbackNames, ", "))
+}
+
+// pick
+// the fallback's action from the game's actions
+func (f Fallback) pick(game *acquire.Game, actions []gmcts.Action) gmcts.Action {
+	switch f {
+	case FallbackRandom:
+		return actions[rand.Intn(len(actions))]
+	case FallbackHeuristic:
+		// searching can shuffle the bank, so it gets its own copy
+		view := *game
+		tree := NewSearchTree(&view, 0)
+		tree.SearchRounds(heuristicRounds)
+		return tree.BestAction()
+	default:
+		return actions[0]
+	}
+}
+
+// Incident
+// a move an agent didn't make itself, and why
+type Incident struct {
+	PlayerId int
+	Agent    string
+	Reason   string
+	// the fallback's action, and its description
+	Action      gmcts.Action
+	Description string
+}
+
+// limitedAgent
+// see WithTimeLimit
+type limitedAgent struct {
+	Agent
+	limit    time.Duration
+	fallback Fallback
+	report   func(Incident)
+
+	// closed once the agent's last SelectAction returns (and it's been told about the end of the game, if that
+	// came first), nil when it isn't still running one it ran out of time for
+	busy chan struct{}
+}
+
+// WithTimeLimit
+// wraps the agent so that it has to pick each action within the limit (there's no limit when it's zero).
+// when it takes too long, returns an error, panics or picks an action which isn't legal, the fallback is
+// played for it and the incident is reported (if report isn't nil). an agent still working on a move it ran out
+// of time for isn't asked for another (or told about the game) until it's done, with the next move's limit
+// counting the wait, and falling back when it isn't done in time. it's only told the game has ended, or started
+// again, once it is
+func WithTimeLimit(agent Agent, limit time.Duration, fallback Fallback, report func(Incident)) Agent {
+	return &limitedAgent{
+		Agent:    agent,
+		limit:    limit,
+		fallback: fallback,
+		report:   report,
+	}
+}
+
+type selection struct {
+	action gmcts.Action
+	err    error
+}
+
+func (a *limitedAgent) SelectAction(ctx context.Context, view *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	if len(actions) == 0 {
+		return nil, errors.New("no actions to select")
+	}
+
+	// an agent still finishing an earlier move can have what's left of the limit once it's done
+	deadline := time.Now().Add(a.limit)
+	if !a.idle(a.limit) {
+		return a.fall(view, actions, "is still working on an earlier move")
+	}
+
+	moveCtx, cancel := ctx, context.CancelFunc(func() {})
+	if a.limit > 0 {
+		moveCtx, cancel = context.WithDeadline(ctx, deadline)
+	}
+	defer cancel()
+
+	// the agent may carry on with its copy after it's been given up on
+	agentView := *view
+	selected := make(chan selection, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				selected <- selection{err: fmt.Errorf("panicked: %v", r)}
+			}
+		}()
+
+		action, err := a.Agent.SelectAction(moveCtx, &agentView, actions)
+		selected <- selection{action: action, err: err}
+	}()
+
+	select {
+	case s := <-selected:
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if s.err != nil {
+			return a.fall(view, actions, "failed: "+s.err.Error())
+		}
+
+		if isLegal(s.action, actions) {
+			return s.action, nil
+		}
+		return a.fall(view, actions, "picked an action which isn't legal")
+
+	case <-moveCtx.Done():
+		a.busy = done
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return a.fall(view, actions, fmt.Sprintf("took longer than %s", a.limit))
+	}
+}
+
+func (a *limitedAgent) Observe(event Event) {
+	if a.idle(0) {
+		a.Agent.Observe(event)
+	}
+}
+
+// OnGameStart
+// waits for an agent which is still busy with its last game to be done with it, so the two never overlap
+func (a *limitedAgent) OnGameStart(seat int, rules acquire.Rules) {
+	if a.busy != nil {
+		<-a.busy
+		a.busy = nil
+	}
+
+	a.Agent.OnGameStart(seat, rules)
+}
+
+// OnGameEnd
+// gives an agent which is still busy up to its limit to finish, so it can be told the result. one which takes
+// longer is told once it's done instead, and can't start another game until then
+func (a *limitedAgent) OnGameEnd(result GameResult) {
+	if a.idle(a.limit) {
+		a.Agent.OnGameEnd(result)
+		return
+	}
+
+	busy, ended := a.busy, make(chan struct{})
+	go func() {
+		defer close(ended)
+		<-busy
+		a.Agent.OnGameEnd(result)
+	}()
+	a.busy = ended
+}
+
+// idle
+// whether the agent has finished with the last move it was given up on, waiting up to the timeout for it
+func (a *limitedAgent) idle(timeout time.Duration) bool {
+	if a.busy == nil {
+		return true
+	}
+
+	select {
+	case <-a.busy:
+		a.busy = nil
+		return true
+	default:
+	}
+
+	if timeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-a.busy:
+		a.busy = nil
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// fall
+// plays the fallback's action, reporting why
+func (a *limitedAgent) fall(view *acquire.Game, actions []gmcts.Action, reason string) (gmcts.Action, error) {
+	action := a.fallback.pick(view, actions)
+
+	if a.report != nil {
+		a.report(Incident{
+			PlayerId:    view.ActivePlayer().Id,
+			Agent:       a.Name(),
+			Reason:      reason,
+			Action:      action,
+			Description: action.(acquire.IAction).String(view),
+		})
+	}
+
+	return action, nil
+}
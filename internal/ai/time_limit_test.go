@@ -0,0 +1,204 @@
+package ai
+
+import (
+	"acquire/internal/acquire"
+	"context"
+	"errors"
+	"git.sr.ht/~bonbon/gmcts"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedAgent
+// picks the last action after a delay, or misbehaves
+type scriptedAgent struct {
+	delay  time.Duration
+	err    error
+	panics bool
+	action gmcts.Action
+}
+
+func (a scriptedAgent) SelectAction(_ *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	time.Sleep(a.delay)
+
+	if a.panics {
+		panic("oh no")
+	}
+	if a.action != nil {
+		return a.action, a.err
+	}
+	return actions[len(actions)-1], a.err
+}
+
+// limitedSelect
+// has the agent select from a new game's first actions within a limit, returning what it picked and the incidents
+func limitedSelect(t *testing.T, agent Agent, times int) ([]gmcts.Action, []gmcts.Action, []Incident) {
+	rand.Seed(9)
+	game := acquire.NewGame()
+	actions := game.GetActions()
+
+	var incidents []Incident
+	limited := WithTimeLimit(agent, 50*time.Millisecond, FallbackFirst, func(incident Incident) {
+		incidents = append(incidents, incident)
+	})
+
+	var picked []gmcts.Action
+	for i := 0; i < times; i++ {
+		action, err := limited.SelectAction(context.Background(), game, actions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		picked = append(picked, action)
+	}
+
+	return actions, picked, incidents
+}
+
+func TestTimeLimit(t *testing.T) {
+	actions, picked, incidents := limitedSelect(t, Adapt(scriptedAgent{}, "quick"), 1)
+	if picked[0] != actions[len(actions)-1] || len(incidents) != 0 {
+		t.Error("an agent within the limit should play its own move")
+	}
+
+	actions, picked, incidents = limitedSelect(t, Adapt(scriptedAgent{delay: 120 * time.Millisecond}, "slow"), 2)
+	if picked[0] != actions[0] || picked[1] != actions[0] || len(incidents) != 2 {
+		t.Fatalf("a slow agent should fall back, got %d incidents", len(incidents))
+	}
+
+	if incidents[0].Agent != "slow" || incidents[0].PlayerId != 1 || !strings.Contains(incidents[0].Reason, "took longer than 50ms") {
+		t.Errorf("unexpected incident %+v", incidents[0])
+	}
+
+	if !strings.Contains(incidents[1].Reason, "still working") {
+		t.Errorf("the agent shouldn't be asked again while it's busy, got %+v", incidents[1])
+	}
+}
+
+func TestTimeLimitMisbehaving(t *testing.T) {
+	tests := map[string]struct {
+		agent  scriptedAgent
+		reason string
+	}{
+		"an error":       {scriptedAgent{err: errors.New("no idea")}, "failed: no idea"},
+		"a panic":        {scriptedAgent{panics: true}, "panicked: oh no"},
+		"an illegal one": {scriptedAgent{action: acquire.Action_PickHotelToFound{Hotel: acquire.TowerHotel}}, "isn't legal"},
+	}
+
+	for name, test := range tests {
+		actions, picked, incidents := limitedSelect(t, Adapt(test.agent, name), 1)
+		if picked[0] != actions[0] || len(incidents) != 1 || !strings.Contains(incidents[0].Reason, test.reason) {
+			t.Errorf("%s: expected to fall back because it %s, got %+v", name, test.reason, incidents)
+		}
+	}
+}
+
+func TestTimeLimitCancelled(t *testing.T) {
+	game := acquire.NewGame()
+	limited := WithTimeLimit(Adapt(scriptedAgent{delay: 50 * time.Millisecond}, "slow"), time.Second, FallbackFirst, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := limited.SelectAction(ctx, game, game.GetActions())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("a cancelled game shouldn't fall back, got %v", err)
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	rand.Seed(10)
+	game := acquire.NewGame()
+	actions := game.GetActions()
+
+	for _, name := range []string{"first", "Random", "heuristic"} {
+		fallback, err := ParseFallback(name)
+		if err != nil || !strings.EqualFold(fallback.String(), name) {
+			t.Fatalf("%s: read back as %s (%v)", name, fallback, err)
+		}
+
+		if _, err := acquire.FindAction(actions, acquire.Notation(fallback.pick(game, actions))); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+
+	if _, err := ParseFallback("panic"); err == nil {
+		t.Error("expected an unknown fallback to be an error")
+	}
+}
+
+// overrunningAgent
+// takes its time over its first move, whatever the context says, and records what it's told
+type overrunningAgent struct {
+	delay  time.Duration
+	events []string
+}
+
+func (a *overrunningAgent) Name() string { return "overrunning" }
+
+func (a *overrunningAgent) OnGameStart(int, acquire.Rules) {
+	a.events = append(a.events, "start")
+}
+
+func (a *overrunningAgent) Observe(Event) {}
+
+func (a *overrunningAgent) SelectAction(_ context.Context, _ *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	time.Sleep(a.delay)
+	a.delay = 0
+	a.events = append(a.events, "select")
+	return actions[len(actions)-1], nil
+}
+
+func (a *overrunningAgent) OnGameEnd(GameResult) {
+	a.events = append(a.events, "end")
+}
+
+// TestTimeLimitOverrunningTheGame
+// an agent still working when its game ends is told so once it's done, and before it starts another
+// (run with -race to be sure it's never used from two goroutines at once)
+func TestTimeLimitOverrunningTheGame(t *testing.T) {
+	game := acquire.NewGame()
+	agent := &overrunningAgent{delay: 200 * time.Millisecond}
+	limited := WithTimeLimit(agent, 20*time.Millisecond, FallbackFirst, nil)
+
+	limited.OnGameStart(1, game.Rules)
+	if _, err := limited.SelectAction(context.Background(), game, game.GetActions()); err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	limited.OnGameEnd(GameResult{})
+	if time.Since(started) > 150*time.Millisecond {
+		t.Error("the end of the game shouldn't wait for the agent for longer than its limit")
+	}
+
+	limited.OnGameStart(1, game.Rules)
+	expected := "start select end start"
+	if events := strings.Join(agent.events, " "); events != expected {
+		t.Errorf("expected the agent to be told %q in order, got %q", expected, events)
+	}
+}
+
+// TestTimeLimitWaitsForTheLastMove
+// an agent which finishes the move it ran out of time for during the next one is asked for that one
+func TestTimeLimitWaitsForTheLastMove(t *testing.T) {
+	game := acquire.NewGame()
+	actions := game.GetActions()
+	agent := &overrunningAgent{delay: 150 * time.Millisecond}
+	limited := WithTimeLimit(agent, 100*time.Millisecond, FallbackFirst, nil)
+
+	picked := make([]gmcts.Action, 2)
+	for i := range picked {
+		action, err := limited.SelectAction(context.Background(), game, actions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		picked[i] = action
+	}
+
+	if picked[0] != actions[0] || picked[1] != actions[len(actions)-1] {
+		t.Errorf("the first move should fall back and the second be the agent's, got %s and %s",
+			acquire.Notation(picked[0]), acquire.Notation(picked[1]))
+	}
+}
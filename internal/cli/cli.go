@@ -0,0 +1,51 @@
+// Package cli
+// what the commands share for reading their command lines
+package cli
+
+import (
+	"acquire/internal/acquire"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Repeated
+// collects each value of a flag which can be given more than once, such as '--seat n=type'
+type Repeated []string
+
+func (r *Repeated) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *Repeated) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+// ReadRules
+// reads a json file of rule variants, any rule left out of the file keeps its default
+func ReadRules(path string) (acquire.Rules, error) {
+	rules := acquire.DefaultRules
+
+	file, err := os.Open(path)
+	if err != nil {
+		return rules, err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(&rules)
+	if err != nil {
+		return rules, fmt.Errorf("reading rules from %s: %w", path, err)
+	}
+
+	err = rules.Validate()
+	if err != nil {
+		return rules, fmt.Errorf("rules in %s: %w", path, err)
+	}
+
+	return rules, nil
+}
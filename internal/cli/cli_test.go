@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepeated(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+
+	var seats Repeated
+	flags.Var(&seats, "seat", "")
+
+	err := flags.Parse([]string{"--seat", "1=human", "--seat", "2=random"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(seats) != 2 || seats.String() != "1=human,2=random" {
+		t.Errorf("every value should be kept, in order, got %v", seats)
+	}
+}
+
+func TestReadRules(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		contents string
+		// the error expected, blank when the rules should be read
+		expected string
+	}{
+		{`{"safe_chain_size": 12}`, ""},
+		{`{"starting_cash": 1}`, "unknown field"},
+		{`{"safe_chain_size": 50}`, "rules in"},
+		{`{"safe_chain_size":`, "reading rules from"},
+	}
+
+	for i, test := range tests {
+		path := filepath.Join(dir, "rules.json")
+		if err := os.WriteFile(path, []byte(test.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		rules, err := ReadRules(path)
+		if test.expected == "" {
+			if err != nil || rules.SafeChainSize != 12 || rules.StartingMoney != 6000 {
+				t.Errorf("%d: rules left out of the file should keep their defaults, got %+v %v", i, rules, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%d: expected an error containing %q, got %v", i, test.expected, err)
+		}
+	}
+
+	if _, err := ReadRules(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("a missing file should be an error")
+	}
+}
@@ -0,0 +1,210 @@
+// Package engine
+// plays the game over a line based text protocol, loosely modelled on chess's UCI, so that bots can be run
+// as separate programs. the controller sends one command per line and the engine answers on its own lines:
+//
+//	acquire                          -> id name <name>, then acquireok
+//	isready                          -> readyok
+//	newgame                          forget the last position, and go back to the default rules
+//	rules <json>                     play the following positions with these rules (acquire.Rules as json)
+//	position <position>              the game as it stands, as written by acquire's OneLinePosition
+//	legal                            -> legal <move> <move> ...
+//	go [rounds <n>] [movetime <ms>]  -> bestmove <move>, for whoever is to play in the position
+//	quit                             stop
+//
+// positions are sent as the player to move sees them (acquire's OneLinePositionFor), so the other players' hands
+// are left out and dealt at random from the unseen tiles. moves are written in acquire's Notation. go searches for the given number of rounds, or for movetime
+// milliseconds, and answers once it's done. anything the engine can't make sense of is answered with
+// "error <reason>", and blank lines are ignored
+package engine
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Engine
+// answers the protocol's commands with moves from a SmartAgent
+type Engine struct {
+	name string
+	// how many rounds to search for when go isn't given a limit
+	rounds int
+
+	rules acquire.Rules
+	game  *acquire.Game
+}
+
+func NewEngine(name string, rounds int) *Engine {
+	return &Engine{
+		name:   name,
+		rounds: rounds,
+		rules:  acquire.DefaultRules,
+	}
+}
+
+// Run
+// reads commands until quit or the end of the input
+func (e *Engine) Run(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		command, args, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if command == "" {
+			continue
+		}
+
+		if command == "quit" {
+			return nil
+		}
+
+		replies, err := e.handle(command, strings.TrimSpace(args))
+		if err != nil {
+			replies = []string{"error " + err.Error()}
+		}
+
+		for _, reply := range replies {
+			if _, err := fmt.Fprintln(out, reply); err != nil {
+				return err
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (e *Engine) handle(command string, args string) ([]string, error) {
+	switch command {
+	case "acquire":
+		return []string{"id name " + e.name, "acquireok"}, nil
+
+	case "isready":
+		return []string{"readyok"}, nil
+
+	case "newgame":
+		e.rules = acquire.DefaultRules
+		e.game = nil
+		return nil, nil
+
+	case "rules":
+		rules := acquire.DefaultRules
+		if err := json.Unmarshal([]byte(args), &rules); err != nil {
+			return nil, fmt.Errorf("rules: %w", err)
+		}
+		if err := rules.Validate(); err != nil {
+			return nil, fmt.Errorf("rules: %w", err)
+		}
+		e.rules = rules
+		return nil, nil
+
+	case "position":
+		game, err := acquire.ParsePositionWithRules(args, e.rules)
+		if err != nil {
+			return nil, fmt.Errorf("position: %w", err)
+		}
+		e.game = game
+		return nil, nil
+
+	case "legal":
+		view, err := e.view()
+		if err != nil {
+			return nil, err
+		}
+
+		moves := []string{"legal"}
+		for _, action := range view.GetActions() {
+			moves = append(moves, acquire.Notation(action))
+		}
+		return []string{strings.Join(moves, " ")}, nil
+
+	case "go":
+		move, err := e.search(args)
+		if err != nil {
+			return nil, err
+		}
+		return []string{"bestmove " + move}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// view
+// a copy of the position for working out moves, as getting the actions can change the game
+func (e *Engine) view() (*acquire.Game, error) {
+	if e.game == nil {
+		return nil, errors.New("there's no position")
+	}
+
+	if e.game.IsTerminal() {
+		return nil, errors.New("the game is over")
+	}
+
+	view := *e.game
+	return &view, nil
+}
+
+// search
+// the best move in the position, within the limits given to go
+func (e *Engine) search(args string) (string, error) {
+	rounds := e.rounds
+	var movetime time.Duration
+
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i += 2 {
+		if i+1 >= len(fields) {
+			return "", fmt.Errorf("go: %s needs a value", fields[i])
+		}
+
+		n, err := strconv.Atoi(fields[i+1])
+		if err != nil || n < 1 {
+			return "", fmt.Errorf("go: %s should be a positive number, was %q", fields[i], fields[i+1])
+		}
+
+		switch fields[i] {
+		case "rounds":
+			rounds = n
+		case "movetime":
+			movetime = time.Duration(n) * time.Millisecond
+		default:
+			return "", fmt.Errorf("go: unknown limit %q", fields[i])
+		}
+	}
+
+	view, err := e.view()
+	if err != nil {
+		return "", err
+	}
+
+	actions := view.GetActions()
+
+	// getting the actions can end the game, when the player has to refresh their hand from an empty bank
+	if view.IsTerminal() {
+		return "", errors.New("the game is over")
+	}
+
+	if len(actions) == 1 {
+		return acquire.Notation(actions[0]), nil
+	}
+
+	agent := ai.NewSmartAgent(rounds)
+	if movetime > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), movetime)
+		defer cancel()
+
+		return acquire.Notation(agent.SelectActionWithin(ctx, view)), nil
+	}
+
+	action, err := agent.SelectAction(view, actions)
+	if err != nil {
+		return "", err
+	}
+
+	return acquire.Notation(action), nil
+}
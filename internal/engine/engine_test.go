@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"acquire/internal/acquire"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// session
+// runs the commands through a new engine, returning its answers
+func session(t *testing.T, commands ...string) []string {
+	out := strings.Builder{}
+	err := NewEngine("test", 20).Run(strings.NewReader(strings.Join(commands, "\n")), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+func TestHandshake(t *testing.T) {
+	replies := session(t, "acquire", "", "isready", "quit", "isready")
+
+	expected := []string{"id name test", "acquireok", "readyok"}
+	if strings.Join(replies, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %q, got %q", expected, replies)
+	}
+}
+
+func TestBestMove(t *testing.T) {
+	rand.Seed(1)
+	game := acquire.NewGame()
+	game.GetActions()
+
+	for _, limit := range []string{"go", "go rounds 10", "go movetime 20"} {
+		replies := session(t, "newgame", "position "+game.OneLinePosition(), "legal", limit)
+		if len(replies) != 2 {
+			t.Fatalf("%s: expected the legal moves and a best move, got %q", limit, replies)
+		}
+
+		legal := strings.Fields(replies[0])
+		move, ok := strings.CutPrefix(replies[1], "bestmove ")
+		if legal[0] != "legal" || !ok {
+			t.Fatalf("%s: unexpected replies %q", limit, replies)
+		}
+
+		if _, err := acquire.FindAction(game.GetActions(), move); err != nil {
+			t.Errorf("%s: %s", limit, err)
+		}
+
+		found := false
+		for _, m := range legal[1:] {
+			found = found || m == move
+		}
+		if !found {
+			t.Errorf("%s: %s isn't in the legal moves %q", limit, move, legal)
+		}
+	}
+}
+
+func TestRules(t *testing.T) {
+	position := "position player 1: $100 / player 2: $100"
+
+	replies := session(t, `rules {"starting_money": 1000}`, position, "legal")
+	if !strings.HasPrefix(replies[0], "legal ") {
+		t.Fatalf("expected the legal moves, got %q", replies)
+	}
+
+	replies = session(t, `rules {"safe_chain_size": 1}`)
+	if !strings.HasPrefix(replies[0], "error rules") {
+		t.Errorf("bad rules should be an error, got %q", replies)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := map[string]string{
+		"go without a position":  "go",
+		"a bad position":         "position colour: blue",
+		"a bad limit":            "go rounds lots",
+		"an unknown limit":       "go depth 3",
+		"a limit without value":  "go rounds",
+		"an unknown command":     "uci",
+		"legal after a new game": "newgame\nlegal",
+	}
+
+	for name, commands := range tests {
+		replies := session(t, commands)
+		if len(replies) != 1 || !strings.HasPrefix(replies[0], "error ") {
+			t.Errorf("%s: expected an error, got %q", name, replies)
+		}
+	}
+}
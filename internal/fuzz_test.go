@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"acquire/internal/acquire"
+	"math/rand"
+	"testing"
+)
+
+// maxActions
+// far more actions than a game could take, a game still going after this many is stuck in a loop
+const maxActions = 10000
+
+// playFuzzedGame
+// plays a game dealt from the seed, taking the action at each index in choices (wrapping around the number of
+// actions available) and then random actions from the seed once they run out. the invariants are checked after
+// every action, and the game has to finish.
+//
+// the fuzzer saves any failing input under testdata/fuzz, which is then run by go test like any other case
+func playFuzzedGame(t *testing.T, seed int64, choices []byte) {
+	rand.Seed(seed)
+	picker := rand.New(rand.NewSource(seed))
+
+	game := acquire.NewGame()
+	if err := game.CheckInvariants(); err != nil {
+		t.Fatalf("new game: %s", err)
+	}
+
+	for n := 0; !game.IsTerminal(); n++ {
+		if n >= maxActions {
+			t.Fatalf("the game didn't finish after %d actions", maxActions)
+		}
+
+		actions := game.GetActions()
+		if game.IsTerminal() {
+			break
+		}
+
+		if len(actions) == 0 {
+			t.Fatalf("action %d: the game isn't over, but there's nothing to do (next action is %s)",
+				n, game.NextActionType.String())
+		}
+
+		var choice int
+		if n < len(choices) {
+			choice = int(choices[n]) % len(actions)
+		} else {
+			choice = picker.Intn(len(actions))
+		}
+
+		action := actions[choice].(acquire.IAction)
+		description := action.String(game)
+
+		newGame, err := game.ApplyAction(action)
+		if err != nil {
+			t.Fatalf("action %d (%s): %s", n, description, err)
+		}
+		game = newGame.(*acquire.Game)
+
+		if err := game.CheckInvariants(); err != nil {
+			t.Fatalf("action %d (%s): %s", n, description, err)
+		}
+	}
+
+	if err := game.CheckInvariants(); err != nil {
+		t.Fatalf("finished game: %s", err)
+	}
+}
+
+func FuzzRandomGame(f *testing.F) {
+	for seed := int64(0); seed < 8; seed++ {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		playFuzzedGame(t, seed, nil)
+	})
+}
+
+func FuzzActionSequence(f *testing.F) {
+	f.Add(int64(1), []byte{})
+	f.Add(int64(2), []byte{0, 0, 0, 0, 0, 0, 0, 0})
+	f.Add(int64(3), []byte{255, 255, 255, 255, 255, 255, 255, 255})
+	f.Add(int64(4), []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+
+	f.Fuzz(func(t *testing.T, seed int64, choices []byte) {
+		playFuzzedGame(t, seed, choices)
+	})
+}
@@ -0,0 +1,692 @@
+package server
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how long a request for events waits for something to happen before answering with nothing
+const pollTimeout = 25 * time.Second
+
+const (
+	// the most games hosted at once, counting finished games which are still kept
+	defaultMaxGames = 64
+	// the most search rounds an mcts seat can be given
+	defaultMaxStrength = 2000
+	// how long remote seats get for each move when the config doesn't give a shorter time
+	defaultRemoteMoveTime = 5 * time.Minute
+	// how long a finished game is kept so its players can see how it ended
+	defaultKeepFinished = 10 * time.Minute
+	// how long a game can go without any of its players being heard from before it's stopped
+	defaultIdleTimeout = 30 * time.Minute
+	// how often games are checked for having finished or gone idle
+	sweepInterval = time.Minute
+	// the largest request body read, well over any game config or action
+	maxBodySize = 64 << 10
+)
+
+// GameConfig
+// the body of POST /games
+type GameConfig struct {
+	// rule variants, merged with the defaults
+	Rules json.RawMessage `json:"rules,omitempty"`
+	Seats []SeatConfig    `json:"seats"`
+
+	// how long every seat gets for each move (e.g. "30s"), and what's played when they don't move in time
+	MoveTime string `json:"move_time,omitempty"`
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// SeatConfig
+// who plays a seat. the agent is remote (when it's left out), random or mcts[:strength] (up to a limit),
+// engines can't be started over http
+type SeatConfig struct {
+	Name  string `json:"name,omitempty"`
+	Agent string `json:"agent,omitempty"`
+}
+
+// Seat
+// a seat of a game hosted over http
+type Seat struct {
+	Seat  int    `json:"seat"`
+	Name  string `json:"name"`
+	Agent string `json:"agent"`
+	// the key a remote player uses to act for the seat, only given out when the game is created
+	Token string `json:"token,omitempty"`
+}
+
+// API
+// hosts games over http, for players who can't keep a connection open. requests and responses are json:
+//
+//	POST /games               creates a game from a GameConfig, answered with its id and seats,
+//	                          each remote seat with the token its player acts for it with
+//	GET  /games/{id}          the game as the token's player sees it (an acquire.View), or as a spectator without one
+//	GET  /games/{id}/actions  the token's player's legal actions, empty when it isn't their turn
+//	POST /games/{id}/actions  plays {"action": "7F"} for the token's player
+//	GET  /games/{id}/events   the game's events from ?after=n (the number already seen), waiting for more if
+//	                          there aren't any yet
+//
+// the token is given as "Authorization: Bearer <token>", or as ?token=<token>. events are Messages, as in the
+// tcp protocol: an event for every action, a turn whenever a remote seat has to act, and a gameover at the end.
+//
+// as anyone can create games, there's a limit to how many are hosted at once and how strong their mcts seats
+// can be. remote seats always have a move time, played for by the fallback when it runs out. finished games are
+// kept for a while before they're forgotten, and games whose players haven't been heard from in a long time
+// are stopped and forgotten
+type API struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	logMu sync.Mutex
+	log   io.Writer
+
+	maxGames       int
+	maxStrength    int
+	remoteMoveTime time.Duration
+	keepFinished   time.Duration
+	idleTimeout    time.Duration
+
+	mu     sync.Mutex
+	games  map[string]*hostedGame
+	nextId int
+}
+
+// NewAPI
+// an API with no games yet, which writes what happens in its games to the log (which can be nil)
+func NewAPI(log io.Writer) *API {
+	if log == nil {
+		log = io.Discard
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	api := &API{
+		ctx:            ctx,
+		cancel:         cancel,
+		log:            log,
+		maxGames:       defaultMaxGames,
+		maxStrength:    defaultMaxStrength,
+		remoteMoveTime: defaultRemoteMoveTime,
+		keepFinished:   defaultKeepFinished,
+		idleTimeout:    defaultIdleTimeout,
+		games:          make(map[string]*hostedGame),
+	}
+
+	go api.sweepEvery(sweepInterval)
+	return api
+}
+
+// Close
+// stops every game which is still being played
+func (api *API) Close() {
+	api.cancel()
+}
+
+// hostedGame
+// a game being played (or which has been played) through the API
+type hostedGame struct {
+	id    string
+	seats []Seat
+	// stops the game
+	cancel context.CancelFunc
+
+	mu sync.Mutex
+	// when one of the game's players was last heard from, or when it finished once it's over
+	lastActive time.Time
+	// the game as of the last thing to happen
+	game   acquire.Game
+	events []Message
+	// the remote seat which has to act, nil while it's nobody's
+	turn *httpTurn
+	over bool
+	// closed and replaced whenever there's a new event, to wake up anyone waiting for one
+	changed chan struct{}
+}
+
+// httpTurn
+// a remote seat's legal actions, and where the one they pick goes
+type httpTurn struct {
+	seat      int
+	actions   []gmcts.Action
+	submitted chan gmcts.Action
+}
+
+func (api *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "games" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "games can only be created")
+			return
+		}
+		api.create(w, r)
+		return
+	}
+
+	api.mu.Lock()
+	g := api.games[parts[1]]
+	api.mu.Unlock()
+
+	if g == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("there's no game %q", parts[1]))
+		return
+	}
+
+	seat, ok := g.seatFor(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "the token isn't for any seat in this game")
+		return
+	}
+	if seat != 0 {
+		g.touch()
+	}
+
+	route := r.Method + " "
+	if len(parts) == 3 {
+		route += parts[2]
+	}
+
+	switch route {
+	case "GET ":
+		g.view(w, seat)
+	case "GET actions":
+		g.legal(w, seat)
+	case "POST actions":
+		g.submit(w, r, seat)
+	case "GET events":
+		g.poll(w, r)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("can't %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// create
+// starts a game from the config in the request
+func (api *API) create(w http.ResponseWriter, r *http.Request) {
+	config := GameConfig{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		writeError(w, readStatus(err), "can't read the game config: "+err.Error())
+		return
+	}
+
+	rules := acquire.DefaultRules
+	if len(config.Rules) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(config.Rules))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&rules); err != nil {
+			writeError(w, http.StatusBadRequest, "rules: "+err.Error())
+			return
+		}
+	}
+	if err := rules.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "rules: "+err.Error())
+		return
+	}
+
+	if len(config.Seats) < 2 || len(config.Seats) > acquire.MAX_PLAYERS {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("a game needs 2-%d seats, there were %d", acquire.MAX_PLAYERS, len(config.Seats)))
+		return
+	}
+
+	var moveTime time.Duration
+	if config.MoveTime != "" {
+		var err error
+		moveTime, err = time.ParseDuration(config.MoveTime)
+		if err != nil || moveTime < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("the move time should be a duration like 30s, was %q", config.MoveTime))
+			return
+		}
+	}
+
+	fallback := ai.FallbackFirst
+	if config.Fallback != "" {
+		var err error
+		fallback, err = ai.ParseFallback(config.Fallback)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	g := &hostedGame{changed: make(chan struct{})}
+
+	agents := make([]ai.Agent, len(config.Seats))
+	names := make([]string, len(config.Seats))
+	for i, seat := range config.Seats {
+		if seat.Agent == "" {
+			seat.Agent = "remote"
+		}
+
+		spec, err := ai.ParseSeat(seat.Agent)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("seat %d: %s", i+1, err))
+			return
+		}
+
+		switch {
+		case spec.Type == ai.SeatEngine:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("seat %d: engines can't be started over http", i+1))
+			return
+		case spec.Type == ai.SeatHuman:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("seat %d: human players join from elsewhere, seat them as remote", i+1))
+			return
+		case spec.Type == ai.SeatMCTS && spec.Strength > api.maxStrength:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("seat %d: mcts seats can search at most %d rounds", i+1, api.maxStrength))
+			return
+		}
+
+		agent := spec.NewAgent(0, nil)
+		token := ""
+		if agent == nil {
+			agent = &httpSeat{game: g, seat: i + 1}
+			token = newToken()
+		}
+
+		agents[i] = agent
+		names[i] = strings.TrimSpace(seat.Name)
+		g.seats = append(g.seats, Seat{Seat: i + 1, Agent: agent.Name(), Token: token})
+	}
+
+	game, err := seatGame(rules, agents, names)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for i := range g.seats {
+		g.seats[i].Name = game.Players[i].Name()
+	}
+	g.game = *game
+
+	ctx, cancel := context.WithCancel(api.ctx)
+	g.cancel = cancel
+	if !api.host(g) {
+		cancel()
+		writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("there are already %d games being hosted, try again later", api.maxGames))
+		return
+	}
+
+	report := func(incident ai.Incident) {
+		api.logf("game %s: %s %s, playing %s instead", g.id, game.GetPlayerById(incident.PlayerId).Name(), incident.Reason, incident.Description)
+	}
+
+	// nobody waits on a remote player for long
+	remoteMoveTime := api.remoteMoveTime
+	if moveTime > 0 && moveTime < remoteMoveTime {
+		remoteMoveTime = moveTime
+	}
+
+	limited := make(map[int]ai.Agent)
+	for i, agent := range agents {
+		limit := moveTime
+		if g.seats[i].Token != "" {
+			limit = remoteMoveTime
+		}
+		limited[game.Players[i].Id] = ai.WithTimeLimit(agent, limit, fallback, report)
+	}
+
+	go g.play(ctx, api, game, limited)
+
+	api.logf("game %s: created for %d players", g.id, len(agents))
+	writeJSON(w, http.StatusCreated, struct {
+		Id    string `json:"id"`
+		Seats []Seat `json:"seats"`
+	}{g.id, g.seats})
+}
+
+// host
+// gives the game an id and adds it to the games, unless there are as many as there can be
+func (api *API) host(g *hostedGame) bool {
+	api.sweep(time.Now())
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if len(api.games) >= api.maxGames {
+		return false
+	}
+
+	api.nextId++
+	g.id = strconv.Itoa(api.nextId)
+	g.lastActive = time.Now()
+	api.games[g.id] = g
+
+	return true
+}
+
+// sweepEvery
+// sweeps the games at the interval until the API is closed
+func (api *API) sweepEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			api.sweep(now)
+		case <-api.ctx.Done():
+			return
+		}
+	}
+}
+
+// sweep
+// forgets the games which finished longer ago than they're kept for, and stops and forgets those whose players
+// haven't been heard from within the idle timeout
+func (api *API) sweep(now time.Time) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	for id, g := range api.games {
+		g.mu.Lock()
+		over, inactive := g.over, now.Sub(g.lastActive)
+		g.mu.Unlock()
+
+		switch {
+		case over && inactive >= api.keepFinished:
+			delete(api.games, id)
+		case !over && inactive >= api.idleTimeout:
+			g.cancel()
+			delete(api.games, id)
+			api.logf("game %s: stopped, nobody has played it for %s", id, api.idleTimeout)
+		}
+	}
+}
+
+// touch
+// counts one of the game's players as having been heard from
+func (g *hostedGame) touch() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.over {
+		g.lastActive = time.Now()
+	}
+}
+
+// play
+// plays the game to the end (or until the context is done), recording what happens
+func (g *hostedGame) play(ctx context.Context, api *API, game *acquire.Game, agents map[int]ai.Agent) {
+	game, err := ai.Play(ctx, game, agents, ai.PlayHooks{
+		OnAction: func(event ai.Event) {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+
+			g.game = *event.Game
+			g.record(Message{
+				Type:        "event",
+				Seat:        event.PlayerId,
+				Action:      acquire.Notation(event.Action),
+				Description: event.Description,
+			})
+		},
+	})
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.game = *game
+	g.over = true
+	g.lastActive = time.Now()
+
+	if err != nil {
+		api.logf("game %s: stopped, %s", g.id, err)
+		g.record(Message{Type: "error", Error: "the game was stopped: " + err.Error()})
+		return
+	}
+
+	winners := make([]int, 0)
+	for _, winner := range game.Winners() {
+		winners = append(winners, int(winner))
+	}
+
+	api.logf("game %s: over, %s", g.id, game.EndReason)
+	g.record(Message{Type: "gameover", Winners: winners, Reason: game.EndReason})
+}
+
+// record
+// adds the event and wakes up anyone waiting for it, the game has to be locked
+func (g *hostedGame) record(event Message) {
+	g.events = append(g.events, event)
+
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// seatFor
+// the seat the request's token is for, 0 when there's no token, and false when there's a token for no seat
+func (g *hostedGame) seatFor(r *http.Request) (int, bool) {
+	token := r.URL.Query().Get("token")
+	if auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = strings.TrimSpace(auth)
+	}
+
+	if token == "" {
+		return 0, true
+	}
+
+	for _, seat := range g.seats {
+		if seat.Token != "" && subtle.ConstantTimeCompare([]byte(seat.Token), []byte(token)) == 1 {
+			return seat.Seat, true
+		}
+	}
+
+	return 0, false
+}
+
+func (g *hostedGame) view(w http.ResponseWriter, seat int) {
+	g.mu.Lock()
+	game := g.game
+	g.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, acquire.NewView(&game, seat))
+}
+
+func (g *hostedGame) legal(w http.ResponseWriter, seat int) {
+	if seat == 0 {
+		writeError(w, http.StatusUnauthorized, "only a seat's player can see its actions, give its token")
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	notations := make([]string, 0)
+	if g.turn != nil && g.turn.seat == seat {
+		for _, action := range g.turn.actions {
+			notations = append(notations, acquire.Notation(action))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, Message{Type: "actions", Seat: seat, Actions: notations})
+}
+
+func (g *hostedGame) submit(w http.ResponseWriter, r *http.Request, seat int) {
+	if seat == 0 {
+		writeError(w, http.StatusUnauthorized, "only a seat's player can act for it, give its token")
+		return
+	}
+
+	msg := Message{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&msg); err != nil {
+		writeError(w, readStatus(err), "can't read the action: "+err.Error())
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.turn == nil || g.turn.seat != seat {
+		writeError(w, http.StatusConflict, "it isn't your turn")
+		return
+	}
+
+	action, err := acquire.FindAction(g.turn.actions, msg.Action)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.turn.submitted <- action
+	g.turn = nil
+
+	writeJSON(w, http.StatusAccepted, Message{Type: "action", Seat: seat, Action: acquire.Notation(action)})
+}
+
+// poll
+// answers with the events after the number the request has already seen, waiting for some if there aren't any
+// (and the game isn't over)
+func (g *hostedGame) poll(w http.ResponseWriter, r *http.Request) {
+	after := 0
+	if s := r.URL.Query().Get("after"); s != "" {
+		var err error
+		after, err = strconv.Atoi(s)
+		if err != nil || after < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("after should be the number of events already seen, was %q", s))
+			return
+		}
+	}
+
+	timer := time.NewTimer(pollTimeout)
+	defer timer.Stop()
+
+	for {
+		g.mu.Lock()
+		events, over, changed := g.events, g.over, g.changed
+		g.mu.Unlock()
+
+		if after > len(events) {
+			after = len(events)
+		}
+
+		if after < len(events) || over {
+			writeEvents(w, events[after:], len(events))
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			writeEvents(w, nil, after)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeEvents
+// answers a poll with the events, and the number to poll after next
+func writeEvents(w http.ResponseWriter, events []Message, next int) {
+	writeJSON(w, http.StatusOK, struct {
+		Events []Message `json:"events"`
+		Next   int       `json:"next"`
+	}{append([]Message{}, events...), next})
+}
+
+// httpSeat
+// a remote player of a game hosted over http, who acts by posting their actions
+type httpSeat struct {
+	game *hostedGame
+	seat int
+}
+
+func (s *httpSeat) Name() string {
+	return "Remote"
+}
+
+func (s *httpSeat) OnGameStart(int, acquire.Rules) {}
+
+func (s *httpSeat) Observe(ai.Event) {}
+
+// SelectAction
+// makes it the seat's turn, and waits for its player to post one of the actions
+func (s *httpSeat) SelectAction(ctx context.Context, view *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	turn := &httpTurn{
+		seat:      s.seat,
+		actions:   actions,
+		submitted: make(chan gmcts.Action, 1),
+	}
+
+	s.game.mu.Lock()
+	// getting the actions can change the player's hand, so they're shown the game as it is now
+	s.game.game = *view
+	s.game.turn = turn
+	s.game.record(Message{Type: "turn", Seat: s.seat})
+	s.game.mu.Unlock()
+
+	defer func() {
+		s.game.mu.Lock()
+		if s.game.turn == turn {
+			s.game.turn = nil
+		}
+		s.game.mu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case action := <-turn.submitted:
+		return action, nil
+	}
+}
+
+func (s *httpSeat) OnGameEnd(ai.GameResult) {}
+
+func (api *API) logf(format string, args ...any) {
+	api.logMu.Lock()
+	defer api.logMu.Unlock()
+
+	fmt.Fprintf(api.log, format+"\n", args...)
+}
+
+// newToken
+// a random token which can't be guessed
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, reason string) {
+	writeJSON(w, status, Message{Type: "error", Error: reason})
+}
+
+// readStatus
+// the status for a request body which couldn't be read, which is too large if it went over maxBodySize
+func readStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"acquire/internal/acquire"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// call
+// makes the request with the token (when it isn't blank), decoding the response into v and returning its status
+func call(t *testing.T, method string, url string, token string, body string, v any) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	if v != nil {
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Fatalf("%s %s answered %s: %s", method, url, data, err)
+		}
+	}
+
+	return resp.StatusCode
+}
+
+type created struct {
+	Id    string `json:"id"`
+	Seats []Seat `json:"seats"`
+}
+
+type polled struct {
+	Events []Message `json:"events"`
+	Next   int       `json:"next"`
+}
+
+func TestAPI(t *testing.T) {
+	api := NewAPI(nil)
+	defer api.Close()
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	game := created{}
+	status := call(t, "POST", srv.URL+"/games", "", `{"seats": [{"name": "Alice"}, {"agent": "random"}], "rules": {"starting_money": 8000}}`, &game)
+	if status != http.StatusCreated || len(game.Seats) != 2 {
+		t.Fatalf("expected the game to be created, got %d %+v", status, game)
+	}
+
+	alice := game.Seats[0]
+	if alice.Name != "Alice" || alice.Agent != "Remote" || alice.Token == "" || game.Seats[1].Token != "" {
+		t.Fatalf("only Alice's remote seat should have a token, got %+v", game.Seats)
+	}
+
+	url := srv.URL + "/games/" + game.Id
+
+	view := acquire.View{}
+	call(t, "GET", url, alice.Token, "", &view)
+	if view.PlayerId != 1 || len(view.Tiles) != acquire.MAX_TILES_IN_HAND || view.Players[0].Money != 8000 {
+		t.Fatalf("Alice should see her hand in a game with 8000 starting money, saw %+v", view)
+	}
+
+	spectator := acquire.View{}
+	call(t, "GET", url+"?token=", "", "", &spectator)
+	if spectator.PlayerId != 0 || len(spectator.Tiles) != 0 {
+		t.Fatal("a spectator shouldn't see any tiles")
+	}
+
+	turns := 0
+	for after, over := 0, false; !over; {
+		events := polled{}
+		call(t, "GET", fmt.Sprintf("%s/events?after=%d", url, after), "", "", &events)
+		if len(events.Events) == 0 {
+			t.Fatal("the game stalled")
+		}
+		after = events.Next
+
+		for _, event := range events.Events {
+			switch event.Type {
+			case "gameover":
+				over = true
+
+			case "turn":
+				if event.Seat != 1 {
+					t.Fatalf("only Alice's seat should have turns, got %+v", event)
+				}
+				turns++
+
+				actions := Message{}
+				call(t, "GET", url+"/actions", alice.Token, "", &actions)
+				if len(actions.Actions) == 0 {
+					t.Fatal("it should be Alice's turn")
+				}
+
+				if status := call(t, "POST", url+"/actions", alice.Token, `{"action": "nonsense"}`, nil); status != http.StatusBadRequest {
+					t.Errorf("an illegal action should be refused, got %d", status)
+				}
+				if status := call(t, "POST", url+"/actions", alice.Token, `{"action": "`+actions.Actions[0]+`"}`, nil); status != http.StatusAccepted {
+					t.Fatalf("the action should be accepted, got %d", status)
+				}
+			}
+		}
+	}
+
+	if turns == 0 {
+		t.Error("Alice should have had turns")
+	}
+
+	call(t, "GET", url, alice.Token, "", &view)
+	if !view.IsOver || view.EndReason == "" {
+		t.Errorf("the game should be over, got %+v", view)
+	}
+
+	events := polled{}
+	call(t, "GET", url+"/events?after=100000", "", "", &events)
+	if len(events.Events) != 0 || events.Next == 0 {
+		t.Errorf("polling a finished game should answer straight away, got %+v", events)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	api := NewAPI(nil)
+	defer api.Close()
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	for body, reason := range map[string]string{
+		`{"seats": [{}]}`: "2-6 seats",
+		`{"seats": [{}, {"agent": "engine:rm -rf /"}]}`:       "seat 2: engines can't be started over http",
+		`{"seats": [{}, {"agent": "robot"}]}`:                 "seat 2: unknown agent type",
+		`{"seats": [{}, {"agent": "human"}]}`:                 "seat 2: human players join from elsewhere",
+		`{"seats": [{}, {"agent": "mcts:1000000"}]}`:          "seat 2: mcts seats can search at most 2000 rounds",
+		`{"seats": [{}, {}], "fallback": "resign"}`:           "unknown fallback",
+		`{"seats": [{}, {}], "move_time": "soon"}`:            "move time",
+		`{"seats": [{}, {}], "rules": {"end_chain_size": 1}}`: "rules: end chain size",
+		`{"sets": []}`: "unknown field",
+	} {
+		msg := Message{}
+		status := call(t, "POST", srv.URL+"/games", "", body, &msg)
+		if status != http.StatusBadRequest || !strings.Contains(msg.Error, reason) {
+			t.Errorf("%s should be refused because %s, got %d %s", body, reason, status, msg.Error)
+		}
+	}
+
+	if status := call(t, "GET", srv.URL+"/games/1", "", "", nil); status != http.StatusNotFound {
+		t.Errorf("a game which doesn't exist should be not found, got %d", status)
+	}
+
+	game := created{}
+	call(t, "POST", srv.URL+"/games", "", `{"seats": [{}, {}]}`, &game)
+	url := srv.URL + "/games/" + game.Id
+
+	if status := call(t, "GET", url, "guess", "", nil); status != http.StatusUnauthorized {
+		t.Errorf("a token for no seat should be refused, got %d", status)
+	}
+	if status := call(t, "GET", url+"/actions", "", "", nil); status != http.StatusUnauthorized {
+		t.Errorf("a spectator shouldn't be able to see actions, got %d", status)
+	}
+	if status := call(t, "POST", url+"/actions", "", `{"action": "5C"}`, nil); status != http.StatusUnauthorized {
+		t.Errorf("a spectator shouldn't be able to act, got %d", status)
+	}
+	if status := call(t, "GET", srv.URL+"/games", "", "", nil); status != http.StatusMethodNotAllowed {
+		t.Errorf("games can't be listed, got %d", status)
+	}
+
+	// bodies too large to be a config or an action aren't read
+	padding := strings.Repeat(" ", maxBodySize)
+	if status := call(t, "POST", srv.URL+"/games", "", padding+`{"seats": [{}, {}]}`, nil); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("an oversized config should be refused, got %d", status)
+	}
+	if status := call(t, "POST", url+"/actions", game.Seats[0].Token, padding+`{"action": "5C"}`, nil); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("an oversized action should be refused, got %d", status)
+	}
+
+	// whoever isn't to play has to wait
+	for _, seat := range game.Seats {
+		actions := Message{}
+		call(t, "GET", url+"/actions", seat.Token, "", &actions)
+		if len(actions.Actions) == 0 {
+			if status := call(t, "POST", url+"/actions", seat.Token, `{"action": "5C"}`, nil); status != http.StatusConflict {
+				t.Errorf("seat %d should have to wait for their turn, got %d", seat.Seat, status)
+			}
+		}
+	}
+
+	// closing the api stops the game
+	api.Close()
+
+	for after, stopped := 0, false; !stopped; {
+		events := polled{}
+		call(t, "GET", fmt.Sprintf("%s/events?after=%d", url, after), "", "", &events)
+		after = events.Next
+
+		for _, event := range events.Events {
+			stopped = stopped || (event.Type == "error" && strings.Contains(event.Error, "context canceled"))
+		}
+	}
+}
+
+// pollUntil
+// polls the game's events until one matches, failing the test if the game ends first
+func pollUntil(t *testing.T, url string, match func(Message) bool) {
+	for after := 0; ; {
+		events := polled{}
+		call(t, "GET", fmt.Sprintf("%s/events?after=%d", url, after), "", "", &events)
+		after = events.Next
+
+		for _, event := range events.Events {
+			if match(event) {
+				return
+			}
+			if event.Type == "gameover" || event.Type == "error" {
+				t.Fatalf("the game ended before the event was seen, with %+v", event)
+			}
+		}
+	}
+}
+
+func TestAPILimits(t *testing.T) {
+	api := NewAPI(nil)
+	defer api.Close()
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	api.maxGames = 2
+	api.remoteMoveTime = 100 * time.Millisecond
+
+	// a remote seat which never acts has its moves played for it, slowly enough that its game is still going
+	// by the end of the test
+	waiting := created{}
+	call(t, "POST", srv.URL+"/games", "", `{"seats": [{}, {"agent": "random"}], "move_time": "1h"}`, &waiting)
+	pollUntil(t, srv.URL+"/games/"+waiting.Id, func(event Message) bool {
+		return event.Type == "event" && event.Seat == 1
+	})
+
+	finished := created{}
+	call(t, "POST", srv.URL+"/games", "", `{"seats": [{"agent": "random"}, {"agent": "random"}]}`, &finished)
+	pollUntil(t, srv.URL+"/games/"+finished.Id, func(event Message) bool {
+		return event.Type == "gameover"
+	})
+
+	msg := Message{}
+	if status := call(t, "POST", srv.URL+"/games", "", `{"seats": [{}, {}]}`, &msg); status != http.StatusServiceUnavailable {
+		t.Fatalf("no more games should be hosted than the limit, got %d %s", status, msg.Error)
+	}
+
+	// finished games are kept for a while, and games are kept going while their players are about
+	url := srv.URL + "/games/" + waiting.Id
+	api.sweep(time.Now().Add(api.keepFinished / 2))
+	if status := call(t, "GET", srv.URL+"/games/"+finished.Id, "", "", nil); status != http.StatusOK {
+		t.Errorf("a game which just finished should still be there, got %d", status)
+	}
+
+	call(t, "GET", url, waiting.Seats[0].Token, "", nil)
+	api.sweep(time.Now().Add(api.keepFinished + time.Second))
+	if status := call(t, "GET", srv.URL+"/games/"+finished.Id, "", "", nil); status != http.StatusNotFound {
+		t.Errorf("a game which finished long ago should be forgotten, got %d", status)
+	}
+	if status := call(t, "GET", url, "", "", nil); status != http.StatusOK {
+		t.Errorf("a game whose player was just heard from should carry on, got %d", status)
+	}
+
+	api.sweep(time.Now().Add(api.idleTimeout + time.Second))
+	if status := call(t, "GET", url, "", "", nil); status != http.StatusNotFound {
+		t.Errorf("a game nobody has played for a long time should be forgotten, got %d", status)
+	}
+
+	if status := call(t, "POST", srv.URL+"/games", "", `{"seats": [{}, {}]}`, nil); status != http.StatusCreated {
+		t.Errorf("the forgotten games shouldn't count towards the limit, got %d", status)
+	}
+}
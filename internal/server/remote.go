@@ -0,0 +1,228 @@
+package server
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// how long a message has to be written to a player before they're taken to have gone
+const sendTimeout = 10 * time.Second
+
+// errDisconnected
+// the remote player's connection has closed, so they won't be making any more moves
+var errDisconnected = errors.New("disconnected")
+
+// remote
+// a player connected over tcp, played as an ai.Agent
+type remote struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	name    string
+	seat    int
+
+	// messages are sent both from the game and in reply to the player
+	sendMu      sync.Mutex
+	encoder     *json.Encoder
+	sendTimeout time.Duration
+	// a message couldn't be sent, so the connection has been closed
+	dropped bool
+
+	mu sync.Mutex
+	// where the player's actions go while they're choosing one, nil when it isn't their turn
+	submitted chan string
+	// closed once the connection is
+	gone chan struct{}
+}
+
+func newRemote(conn net.Conn) *remote {
+	return &remote{
+		conn:        conn,
+		scanner:     bufio.NewScanner(conn),
+		encoder:     json.NewEncoder(conn),
+		sendTimeout: sendTimeout,
+		gone:        make(chan struct{}),
+	}
+}
+
+// read
+// the next message from the player. once the connection can't be read, because it's closed or broken,
+// the error is errDisconnected
+func (r *remote) read() (Message, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return Message{}, fmt.Errorf("%w: %s", errDisconnected, err)
+		}
+		return Message{}, errDisconnected
+	}
+
+	msg := Message{}
+	err := json.Unmarshal(r.scanner.Bytes(), &msg)
+	return msg, err
+}
+
+// listen
+// reads the player's messages until they disconnect, passing on their actions
+func (r *remote) listen() {
+	defer close(r.gone)
+
+	for {
+		msg, err := r.read()
+		if errors.Is(err, errDisconnected) {
+			return
+		}
+
+		switch {
+		case err != nil:
+			r.sendError("can't read message: " + err.Error())
+		case msg.Type == "action":
+			r.submit(msg.Action)
+		default:
+			r.sendError("unexpected message type " + strconv.Quote(msg.Type))
+		}
+	}
+}
+
+// submit
+// hands the action to the game if the player is choosing one
+func (r *remote) submit(action string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.submitted == nil {
+		r.sendError("it isn't your turn")
+		return
+	}
+
+	select {
+	case r.submitted <- action:
+	default:
+		r.sendError("an action has already been sent")
+	}
+}
+
+// send
+// writes the message to the player. a player who can't be sent to within the timeout (or at all) is disconnected,
+// which the game notices when their connection stops being read, and plays on without them
+func (r *remote) send(msg Message) {
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+
+	if r.dropped {
+		return
+	}
+
+	r.conn.SetWriteDeadline(time.Now().Add(r.sendTimeout))
+	if err := r.encoder.Encode(msg); err != nil {
+		r.dropped = true
+		r.close()
+	}
+}
+
+func (r *remote) sendError(reason string) {
+	r.send(Message{Type: "error", Error: reason})
+}
+
+func (r *remote) sendState(game *acquire.Game) {
+	view := acquire.NewView(game, r.seat)
+	r.send(Message{Type: "state", State: &view})
+}
+
+// fail
+// tells a player who couldn't join why, then disconnects them
+func (r *remote) fail(reason string) {
+	r.sendError(reason)
+	r.close()
+}
+
+func (r *remote) close() {
+	r.conn.Close()
+}
+
+// displayName
+// the name the player joined with, or their seat when they didn't give one
+func (r *remote) displayName() string {
+	if r.name != "" {
+		return r.name
+	}
+
+	return "Player " + strconv.Itoa(r.seat)
+}
+
+func (r *remote) Name() string {
+	return "Remote"
+}
+
+func (r *remote) OnGameStart(int, acquire.Rules) {}
+
+func (r *remote) Observe(event ai.Event) {
+	r.send(Message{
+		Type:        "event",
+		Seat:        event.PlayerId,
+		Action:      acquire.Notation(event.Action),
+		Description: event.Description,
+	})
+	r.sendState(event.Game)
+}
+
+// SelectAction
+// sends the player their view and legal actions, then waits for them to pick one
+func (r *remote) SelectAction(ctx context.Context, view *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	submitted := make(chan string, 1)
+
+	r.mu.Lock()
+	r.submitted = submitted
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.submitted = nil
+		r.mu.Unlock()
+	}()
+
+	notations := make([]string, len(actions))
+	for i, action := range actions {
+		notations[i] = acquire.Notation(action)
+	}
+
+	// getting the actions can change the player's hand, so they're sent it again
+	r.sendState(view)
+	r.send(Message{Type: "actions", Actions: notations})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-r.gone:
+			return nil, errDisconnected
+
+		case notation := <-submitted:
+			action, err := acquire.FindAction(actions, notation)
+			if err == nil {
+				return action, nil
+			}
+
+			r.sendError(err.Error())
+		}
+	}
+}
+
+func (r *remote) OnGameEnd(result ai.GameResult) {
+	view := acquire.NewView(result.Game, r.seat)
+	r.send(Message{
+		Type:    "gameover",
+		Winners: result.Winners,
+		Reason:  result.EndReason,
+		State:   &view,
+	})
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+)
+
+// seatGame
+// a new game for the agents, one seat each, with each player described by their agent and given their name
+// (a blank name keeps the default)
+func seatGame(rules acquire.Rules, agents []ai.Agent, names []string) (*acquire.Game, error) {
+	game := acquire.NewGameWithRules(rules)
+
+	// unset unused players
+	for i := len(agents); i < len(game.Players); i++ {
+		game.Players[i].Id = 0
+	}
+
+	for i, agent := range agents {
+		err := game.SetPlayerIdentity(game.Players[i].Id, names[i], agent.Name())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return game, nil
+}
@@ -0,0 +1,322 @@
+// Package server
+// hosts games for players on other machines, over a protocol of json lines on tcp. every line is a Message,
+// with its type saying which of the other fields are set. a client connects and joins the table first:
+//
+//	-> {"type": "join", "name": "Alice", "seat": 2}   the seat is optional, leave it out for any open seat
+//	<- {"type": "welcome", "seat": 2}                 or an error, after which the connection is closed
+//
+// once every remote seat has been taken the game starts. each player is sent the game as they see it
+// (see acquire.View) at the start and after every action, along with what happened:
+//
+//	<- {"type": "state", "state": {...}}
+//	<- {"type": "event", "seat": 1, "action": "5C", "description": "..."}
+//
+// when it's their turn a player is sent their legal actions, in acquire's Notation, and answers with one of
+// them. an action which isn't legal, or isn't expected, is answered with an error and can be tried again:
+//
+//	<- {"type": "actions", "actions": ["5C", "7F", ...]}
+//	-> {"type": "action", "action": "7F"}
+//	<- {"type": "error", "error": "..."}
+//
+// once the game is over everyone is sent the result, and their connection is closed:
+//
+//	<- {"type": "gameover", "winners": [2], "reason": "...", "state": {...}}
+//
+// games can also be hosted over http instead, see API
+package server
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// how long a new connection has to join before it's dropped
+const joinTimeout = 10 * time.Second
+
+// Message
+// one line of the protocol, in either direction. only the fields its type uses are set
+type Message struct {
+	Type string `json:"type"`
+
+	// join, welcome and event
+	Name string `json:"name,omitempty"`
+	Seat int    `json:"seat,omitempty"`
+
+	// action and event, and the choices in actions
+	Action      string   `json:"action,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Actions     []string `json:"actions,omitempty"`
+
+	// state and gameover
+	State   *acquire.View `json:"state,omitempty"`
+	Winners []int         `json:"winners,omitempty"`
+	Reason  string        `json:"reason,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Table
+// a single game, with some seats played by agents on the server and the rest by remote players
+type Table struct {
+	rules    acquire.Rules
+	agents   []ai.Agent
+	moveTime time.Duration
+	fallback ai.Fallback
+	log      io.Writer
+
+	mu sync.Mutex
+	// the remote player in each seat, nil until someone joins it
+	remotes []*remote
+	// remote seats which are still to be joined, closing full once there are none
+	open int
+	full chan struct{}
+}
+
+// NewTable
+// a table for len(seats) players, where each seat is played by its agent or, when that's nil, by whoever joins it.
+// what happens at the table is written to the log, which can be nil
+func NewTable(rules acquire.Rules, seats []ai.Agent, log io.Writer) *Table {
+	if log == nil {
+		log = io.Discard
+	}
+
+	t := &Table{
+		rules:   rules,
+		agents:  seats,
+		log:     log,
+		remotes: make([]*remote, len(seats)),
+		full:    make(chan struct{}),
+	}
+
+	for _, agent := range seats {
+		if agent == nil {
+			t.open++
+		}
+	}
+	if t.open == 0 {
+		close(t.full)
+	}
+
+	return t
+}
+
+// LimitMoves
+// gives every seat, remote or not, the limit to pick each action within, playing the fallback for them when they
+// don't (see ai.WithTimeLimit). without a limit, the fallback is still played for seats which fail or disconnect
+func (t *Table) LimitMoves(limit time.Duration, fallback ai.Fallback) {
+	t.moveTime = limit
+	t.fallback = fallback
+}
+
+// Serve
+// seats players who connect to the listener until every remote seat is taken, then plays the game and returns
+// it once it's over. the listener is closed by the time Serve returns
+func (t *Table) Serve(ctx context.Context, listener net.Listener) (*acquire.Game, error) {
+	if len(t.agents) < 2 || len(t.agents) > acquire.MAX_PLAYERS {
+		listener.Close()
+		return nil, fmt.Errorf("a table needs 2-%d seats, there were %d", acquire.MAX_PLAYERS, len(t.agents))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the listener is closed as soon as the context is done, and is always closed by the time Serve returns
+	defer listener.Close()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	go t.accept(listener)
+
+	defer t.closeRemotes()
+
+	select {
+	case <-t.full:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return t.play(ctx)
+}
+
+// accept
+// has everyone who connects join, until the listener is closed
+func (t *Table) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go t.join(conn)
+	}
+}
+
+// join
+// seats the connection if its first message asks to join an open seat, then listens to it for the rest of the game
+func (t *Table) join(conn net.Conn) {
+	r := newRemote(conn)
+
+	conn.SetReadDeadline(time.Now().Add(joinTimeout))
+	msg, err := r.read()
+	if err != nil {
+		r.fail(fmt.Sprintf("expected to join: %s", err))
+		return
+	}
+	if msg.Type != "join" {
+		r.fail(fmt.Sprintf("expected to join, not %q", msg.Type))
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	r.name = msg.Name
+	if err := t.take(msg.Seat, r); err != nil {
+		r.fail(err.Error())
+		return
+	}
+
+	// the game can't start until the player has been welcomed, so that it's the first thing they're sent
+	r.send(Message{Type: "welcome", Seat: r.seat})
+	t.logf("%s joined seat %d", r.displayName(), r.seat)
+	t.seated()
+
+	r.listen()
+}
+
+// take
+// reserves the seat (counting from 1) for the remote player, or the first open seat when it's 0
+func (t *Table) take(seat int, r *remote) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if seat < 0 || seat > len(t.agents) {
+		return fmt.Errorf("there's no seat %d, the table has %d", seat, len(t.agents))
+	}
+
+	for i := range t.agents {
+		if seat != 0 && i != seat-1 {
+			continue
+		}
+
+		if t.agents[i] == nil && t.remotes[i] == nil {
+			r.seat = i + 1
+			t.remotes[i] = r
+			return nil
+		}
+	}
+
+	if seat != 0 {
+		return fmt.Errorf("seat %d isn't open", seat)
+	}
+	return errors.New("the table is full")
+}
+
+// seated
+// counts a remote player as ready to play, starting the game once everyone is
+func (t *Table) seated() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.open--
+	if t.open == 0 {
+		close(t.full)
+	}
+}
+
+// play
+// plays the game with everyone seated
+func (t *Table) play(ctx context.Context) (*acquire.Game, error) {
+	t.mu.Lock()
+	remotes := append([]*remote(nil), t.remotes...)
+	t.mu.Unlock()
+
+	seated := append([]ai.Agent(nil), t.agents...)
+	names := make([]string, len(seated))
+	for i, r := range remotes {
+		if r != nil {
+			seated[i] = r
+			names[i] = r.name
+		}
+	}
+
+	game, err := seatGame(t.rules, seated, names)
+	if err != nil {
+		return nil, err
+	}
+
+	report := func(incident ai.Incident) {
+		t.logf("%s %s, playing %s instead", game.GetPlayerById(incident.PlayerId).Name(), incident.Reason, incident.Description)
+	}
+
+	agents := make(map[int]ai.Agent)
+	for i, agent := range seated {
+		id := game.Players[i].Id
+
+		agents[id] = ai.WithTimeLimit(agent, t.moveTime, t.fallback, report)
+		if r := remotes[i]; r != nil {
+			agents[id] = remoteLimit{Agent: agents[id], remote: r}
+		}
+	}
+
+	// everyone gets to see the game before the first move
+	for _, r := range remotes {
+		if r != nil {
+			r.sendState(game)
+		}
+	}
+
+	game, err = ai.Play(ctx, game, agents, ai.PlayHooks{
+		OnAction: func(event ai.Event) {
+			t.logf("%s", event.Description)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t.logf("game over: %s", game.EndReason)
+	return game, nil
+}
+
+// remoteLimit
+// a remote player under a time limit, who's still told about the game while a move they ran out of time for
+// is being given up on, as their connection doesn't wait for it
+type remoteLimit struct {
+	ai.Agent
+	remote *remote
+}
+
+func (l remoteLimit) Observe(event ai.Event) {
+	l.remote.Observe(event)
+}
+
+func (l remoteLimit) OnGameEnd(result ai.GameResult) {
+	l.remote.OnGameEnd(result)
+}
+
+func (t *Table) closeRemotes() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, r := range t.remotes {
+		if r != nil {
+			r.close()
+		}
+	}
+}
+
+// logf
+// writes a line to the log, which players joining and the game can do at the same time
+func (t *Table) logf(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fmt.Fprintf(t.log, format+"\n", args...)
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// client
+// a scripted remote player
+type client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+func dial(t *testing.T, addr string) *client {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+	return &client{conn: conn, scanner: bufio.NewScanner(conn)}
+}
+
+func (c *client) send(msg Message) {
+	data, _ := json.Marshal(msg)
+	c.conn.Write(append(data, '\n'))
+}
+
+// read
+// the next message from the server, false once it's disconnected
+func (c *client) read() (Message, bool) {
+	if !c.scanner.Scan() {
+		return Message{}, false
+	}
+
+	msg := Message{}
+	if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil {
+		return Message{Type: "unreadable", Error: err.Error()}, true
+	}
+	return msg, true
+}
+
+// join
+// asks for the seat, returning the reply
+func (c *client) join(name string, seat int) Message {
+	c.send(Message{Type: "join", Name: name, Seat: seat})
+	msg, _ := c.read()
+	return msg
+}
+
+// play
+// plays the first legal action whenever it's offered, after trying a move which doesn't exist,
+// returning every message until the server disconnects
+func (c *client) play() []Message {
+	var messages []Message
+	for {
+		msg, ok := c.read()
+		if !ok {
+			return messages
+		}
+		messages = append(messages, msg)
+
+		if msg.Type == "actions" {
+			c.send(Message{Type: "action", Action: "nonsense"})
+			c.send(Message{Type: "action", Action: msg.Actions[0]})
+		}
+	}
+}
+
+type served struct {
+	game *acquire.Game
+	err  error
+}
+
+// serve
+// starts the table on a free port, returning its address and where its result will be sent
+func serve(t *testing.T, table *Table) (string, chan served) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := make(chan served, 1)
+	go func() {
+		game, err := table.Serve(context.Background(), listener)
+		result <- served{game, err}
+	}()
+
+	return listener.Addr().String(), result
+}
+
+func TestServe(t *testing.T) {
+	rand.Seed(3)
+	log := &bytes.Buffer{}
+	table := NewTable(acquire.DefaultRules, []ai.Agent{nil, ai.Adapt(ai.NewStupidAgent(), "Random"), nil}, log)
+	addr, result := serve(t, table)
+
+	alice, bob := dial(t, addr), dial(t, addr)
+	if welcome := alice.join("Alice", 3); welcome.Type != "welcome" || welcome.Seat != 3 {
+		t.Fatalf("Alice should have been given seat 3, got %+v", welcome)
+	}
+	if welcome := bob.join("Bob", 0); welcome.Type != "welcome" || welcome.Seat != 1 {
+		t.Fatalf("Bob should have been given the open seat, got %+v", welcome)
+	}
+
+	seen := make(chan []Message, 1)
+	go func() { seen <- alice.play() }()
+	bobSeen := bob.play()
+	aliceSeen := <-seen
+
+	served := <-result
+	if served.err != nil {
+		t.Fatal(served.err)
+	}
+
+	for seat, messages := range map[int][]Message{1: bobSeen, 3: aliceSeen} {
+		turns, errors := 0, 0
+		for _, msg := range messages {
+			if msg.State != nil && (msg.State.PlayerId != seat || len(msg.State.Tiles) != msg.State.Players[seat-1].NumTiles) {
+				t.Fatalf("seat %d should only see their own tiles, saw %+v", seat, msg.State)
+			}
+
+			switch msg.Type {
+			case "actions":
+				turns++
+			case "error":
+				errors++
+			}
+		}
+
+		if turns == 0 || errors != turns {
+			t.Errorf("seat %d should have had their nonsense refused every turn, %d errors in %d turns", seat, errors, turns)
+		}
+
+		last := messages[len(messages)-1]
+		if last.Type != "gameover" || len(last.Winners) == 0 || last.Reason != served.game.EndReason || !last.State.IsOver {
+			t.Errorf("seat %d should have been told the game is over, got %+v", seat, last)
+		}
+	}
+
+	if served.game.Players[2].Name() != "Alice" || served.game.Players[2].Agent != "Remote" {
+		t.Errorf("seat 3 should be Alice, was %s", served.game.Players[2].Description())
+	}
+
+	if !strings.Contains(log.String(), "Alice joined seat 3") || !strings.Contains(log.String(), "game over") {
+		t.Errorf("unexpected log:\n%s", log.String())
+	}
+}
+
+func TestJoinErrors(t *testing.T) {
+	table := NewTable(acquire.DefaultRules, []ai.Agent{nil, ai.Adapt(ai.NewStupidAgent(), "Random")}, nil)
+	addr, result := serve(t, table)
+
+	refused := func(msg Message, reason string) {
+		c := dial(t, addr)
+		c.send(msg)
+
+		reply, _ := c.read()
+		if reply.Type != "error" || !strings.Contains(reply.Error, reason) {
+			t.Errorf("%+v should have been refused because %s, got %+v", msg, reason, reply)
+		}
+
+		if _, ok := c.read(); ok {
+			t.Error("a player who couldn't join should be disconnected")
+		}
+	}
+
+	refused(Message{Type: "action", Action: "5C"}, "expected to join")
+	refused(Message{Type: "join", Seat: 5}, "there's no seat 5")
+	refused(Message{Type: "join", Seat: 2}, "seat 2 isn't open")
+
+	player := dial(t, addr)
+	if welcome := player.join("", 0); welcome.Seat != 1 {
+		t.Fatalf("expected seat 1, got %+v", welcome)
+	}
+
+	refused(Message{Type: "join"}, "the table is full")
+
+	player.play()
+	if served := <-result; served.err != nil {
+		t.Fatal(served.err)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	log := &bytes.Buffer{}
+	table := NewTable(acquire.DefaultRules, []ai.Agent{nil, nil}, log)
+	table.LimitMoves(time.Second, ai.FallbackRandom)
+	addr, result := serve(t, table)
+
+	stays, leaves := dial(t, addr), dial(t, addr)
+	stays.join("Stays", 1)
+	leaves.join("Leaves", 2)
+	leaves.conn.Close()
+
+	messages := stays.play()
+	if served := <-result; served.err != nil {
+		t.Fatal(served.err)
+	}
+
+	if messages[len(messages)-1].Type != "gameover" {
+		t.Error("the game should carry on without the player who left")
+	}
+
+	if !strings.Contains(log.String(), "Leaves failed: disconnected, playing") {
+		t.Errorf("moves should have been played for the player who left, log:\n%s", log.String())
+	}
+}
+
+func TestServeCancelled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	table := NewTable(acquire.DefaultRules, []ai.Agent{nil, nil}, nil)
+	if _, err := table.Serve(ctx, listener); err != context.DeadlineExceeded {
+		t.Errorf("expected to give up waiting for players, got %v", err)
+	}
+
+	if _, err := listener.Accept(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("the listener should be closed, got %v", err)
+	}
+}
+
+func TestStalledPlayer(t *testing.T) {
+	client, conn := net.Pipe()
+	defer client.Close()
+
+	// nothing is ever read from the client's end, so every write blocks
+	r := newRemote(conn)
+	r.sendTimeout = 20 * time.Millisecond
+	go r.listen()
+
+	game := acquire.NewGame()
+	picked := make(chan error, 1)
+	go func() {
+		_, err := r.SelectAction(context.Background(), game, game.GetActions())
+		picked <- err
+	}()
+
+	select {
+	case err := <-picked:
+		if err != errDisconnected {
+			t.Errorf("a player who can't be sent to should be disconnected, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the game is still waiting on a player who isn't reading")
+	}
+
+	if _, err := client.Read(make([]byte, 1)); err == nil {
+		t.Error("the player's connection should have been closed")
+	}
+}
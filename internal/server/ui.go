@@ -0,0 +1,22 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed web
+var web embed.FS
+
+// UI
+// serves the browser ui, a page for setting up and playing games through the API. it expects the API to be served
+// next to it, under games/
+func UI() http.Handler {
+	files, err := fs.Sub(web, "web")
+	if err != nil {
+		panic(err)
+	}
+
+	return http.FileServer(http.FS(files))
+}
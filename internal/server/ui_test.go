@@ -0,0 +1,32 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUI(t *testing.T) {
+	srv := httptest.NewServer(UI())
+	defer srv.Close()
+
+	for path, expected := range map[string]string{
+		"/":          "<title>Acquire</title>",
+		"/app.js":    "games/${gameId}/actions",
+		"/style.css": "--tower",
+	} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), expected) {
+			t.Errorf("%s should be served with %q, got %s", path, expected, resp.Status)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+// the browser ui for games hosted by the server's http api (see server.API). without a game in the address it
+// sets one up, and with ?game=id&token=token it plays the token's seat (or watches, without a token)
+"use strict";
+
+const ROWS = "ABCDEFGHI";
+const COLUMNS = 12;
+
+const CHAIN_COLORS = {
+	W: "var(--worldwide)",
+	S: "var(--sackson)",
+	F: "var(--festival)",
+	I: "var(--imperial)",
+	A: "var(--american)",
+	C: "var(--continental)",
+	T: "var(--tower)",
+};
+
+const SEAT_TYPES = [
+	["remote", "A friend (remote)"],
+	["random", "Random"],
+	["mcts:200", "AI (easy)"],
+	["mcts:1000", "AI (hard)"],
+];
+
+const params = new URLSearchParams(location.search);
+const gameId = params.get("game");
+const token = params.get("token") || "";
+
+// the game as this seat sees it, and the actions it can take right now
+let view = null;
+let actions = [];
+let over = false;
+// the number of actions played, so that actions fetched from before one was played aren't offered after it
+let played = 0;
+// the shares picked to buy so far, by chain initial
+let purchase = {};
+
+function $(id) {
+	return document.getElementById(id);
+}
+
+function el(tag, props, ...children) {
+	const e = document.createElement(tag);
+	Object.assign(e, props || {});
+	e.append(...children);
+	return e;
+}
+
+function sleep(ms) {
+	return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+async function api(method, path, body) {
+	const headers = {};
+	if (token) {
+		headers["Authorization"] = "Bearer " + token;
+	}
+	if (body !== undefined) {
+		headers["Content-Type"] = "application/json";
+	}
+
+	const resp = await fetch(path, {method, headers, body: body === undefined ? undefined : JSON.stringify(body)});
+	const data = await resp.json();
+	if (!resp.ok) {
+		throw new Error(data.error || resp.statusText);
+	}
+	return data;
+}
+
+function showError(err) {
+	$("error").textContent = err ? err.message : "";
+}
+
+// setting up a game
+
+function addOpponent(type) {
+	const select = el("select", {name: "agent"});
+	for (const [value, label] of SEAT_TYPES) {
+		select.append(el("option", {value, textContent: label, selected: value === type}));
+	}
+
+	const row = el("div", {}, el("span"), select);
+	row.append(el("button", {
+		type: "button",
+		textContent: "Remove",
+		onclick: () => {
+			row.remove();
+			updateSetup();
+		},
+	}));
+
+	$("opponents").append(row);
+	updateSetup();
+}
+
+function updateSetup() {
+	const rows = [...$("opponents").children];
+	rows.forEach((row, i) => {
+		row.querySelector("span").textContent = `Player ${i + 2} `;
+		row.querySelector("button").disabled = rows.length <= 1;
+	});
+
+	$("add-opponent").disabled = rows.length >= 5;
+}
+
+async function createGame(event) {
+	event.preventDefault();
+	showError(null);
+
+	const form = event.target;
+	const seats = [{name: form.elements.name.value.trim(), agent: "remote"}];
+	for (const select of $("opponents").querySelectorAll("select")) {
+		seats.push({agent: select.value});
+	}
+
+	let created;
+	try {
+		created = await api("POST", "games", {seats});
+	} catch (err) {
+		showError(err);
+		return;
+	}
+
+	const link = (seat) => new URL(`?game=${created.id}&token=${seat.token}`, location.href).href;
+	const invited = created.seats.slice(1).filter((seat) => seat.token);
+	if (invited.length === 0) {
+		location.href = link(created.seats[0]);
+		return;
+	}
+
+	for (const seat of invited) {
+		const url = link(seat);
+		$("invite-links").append(el("li", {}, `${seat.name}: `, el("a", {href: url, textContent: url})));
+	}
+	$("play-link").href = link(created.seats[0]);
+
+	form.hidden = true;
+	$("invites").hidden = false;
+}
+
+// playing a game
+
+async function follow() {
+	let after = 0;
+
+	for (;;) {
+		let polled;
+		try {
+			if (after === 0) {
+				await refresh();
+			}
+			polled = await api("GET", `games/${gameId}/events?after=${after}`);
+		} catch (err) {
+			showError(err);
+			await sleep(2000);
+			continue;
+		}
+
+		after = polled.next;
+		for (const event of polled.events) {
+			record(event);
+		}
+
+		if (polled.events.length > 0) {
+			try {
+				await refresh();
+			} catch (err) {
+				showError(err);
+			}
+		}
+
+		if (over) {
+			return;
+		}
+	}
+}
+
+async function refresh() {
+	const before = played;
+	view = await api("GET", `games/${gameId}`);
+
+	let legal = [];
+	if (token && !view.is_over) {
+		legal = (await api("GET", `games/${gameId}/actions`)).actions;
+	}
+
+	// an action was played meanwhile, the game will tell us once it's happened
+	if (played !== before) {
+		legal = [];
+	}
+
+	if (legal.join() !== actions.join()) {
+		purchase = {};
+	}
+	actions = legal;
+
+	render();
+}
+
+function record(event) {
+	switch (event.type) {
+	case "event":
+		log(event.description);
+		break;
+	case "gameover":
+		over = true;
+		log(`Game over: ${event.reason}. Won by ${event.winners.map(playerName).join(", ")}`);
+		break;
+	case "error":
+		over = true;
+		log(event.error);
+		break;
+	}
+}
+
+function log(line) {
+	$("log").prepend(el("li", {textContent: line}));
+}
+
+async function play(notation) {
+	showError(null);
+
+	try {
+		await api("POST", `games/${gameId}/actions`, {action: notation});
+	} catch (err) {
+		showError(err);
+		return;
+	}
+
+	// the game carries on once the action is played, and tells us about it
+	played++;
+	actions = [];
+	render();
+}
+
+function playerName(id) {
+	const player = view && view.players.find((p) => p.id === id);
+	return player ? player.name : `Player ${id}`;
+}
+
+function chainBadge(chain) {
+	return el("span", {
+		className: `chain ${chain.initial}` + (chain.safe ? " safe" : ""),
+		textContent: chain.initial,
+		title: chain.name + (chain.safe ? " (safe)" : ""),
+	});
+}
+
+function isTile(notation) {
+	return /^\d+[A-I]$/.test(notation);
+}
+
+// label
+// describes an action's notation (see acquire.Notation)
+function label(notation) {
+	const chainName = (initial) => {
+		const chain = view.chains.find((c) => c.initial === initial);
+		return chain ? chain.name : initial;
+	};
+
+	const [kind, arg] = notation.split(":");
+	switch (kind) {
+	case "skip":
+		return "Skip placing a tile";
+	case "found":
+		return `Found ${chainName(arg)}`;
+	case "acquirer":
+		return `${chainName(arg)} acquires`;
+	case "hold":
+		return "Hold every share";
+	case "pass":
+		return "Buy nothing";
+	}
+
+	if (kind === "sell" || kind === "trade") {
+		return notation.split(",").map((part) => part.replace(":", " ")).join(", ").replace(/^./, (c) => c.toUpperCase());
+	}
+
+	return notation;
+}
+
+function render() {
+	renderStatus();
+	renderBoard();
+	renderHand();
+	renderActions();
+	renderInventories();
+}
+
+function renderStatus() {
+	if (view.is_over) {
+		$("status").textContent = `Game over: ${view.end_reason}`;
+		return;
+	}
+
+	const yours = token && view.active_player === view.player_id;
+	const who = yours ? "Your turn" : `${playerName(view.active_player)} to play`;
+	$("status").textContent = `Turn ${view.turn} · ${who} · ${view.phase} · ${view.tiles_left} tiles left`;
+}
+
+function renderBoard() {
+	const board = $("board");
+	board.replaceChildren();
+
+	const head = el("tr", {}, el("th"));
+	for (let x = 1; x <= COLUMNS; x++) {
+		head.append(el("th", {textContent: x}));
+	}
+	board.append(head);
+
+	view.board.forEach((row, y) => {
+		const tr = el("tr", {}, el("th", {textContent: ROWS[y].toLowerCase()}));
+
+		[...row].forEach((cell, x) => {
+			const tile = `${x + 1}${ROWS[y]}`;
+			const td = el("td", {title: tile});
+
+			if (cell === "=") {
+				td.className = "lone";
+			} else if (cell !== ".") {
+				td.textContent = cell;
+				td.style.background = CHAIN_COLORS[cell];
+			} else if (actions.includes(tile)) {
+				td.className = "legal";
+				td.textContent = tile;
+				td.onclick = () => play(tile);
+			}
+
+			if (tile === view.last_placed) {
+				td.classList.add("last");
+			}
+			tr.append(td);
+		});
+
+		board.append(tr);
+	});
+}
+
+function renderHand() {
+	const hand = $("hand");
+	hand.replaceChildren();
+
+	if (!token) {
+		return;
+	}
+
+	hand.append("Your tiles: ");
+	for (const tile of view.tiles || []) {
+		hand.append(el("button", {textContent: tile, disabled: !actions.includes(tile), onclick: () => play(tile)}));
+	}
+}
+
+function renderActions() {
+	const box = $("actions");
+	box.replaceChildren();
+
+	for (const notation of actions) {
+		if (!isTile(notation) && notation !== "pass" && !notation.startsWith("buy:")) {
+			box.append(el("button", {textContent: label(notation), onclick: () => play(notation)}));
+		}
+	}
+
+	if (actions.includes("pass")) {
+		renderPurchase(box);
+	}
+}
+
+// parseBuy
+// the shares bought by a buy action, by chain initial
+function parseBuy(notation) {
+	const shares = {};
+	for (const part of notation.slice("buy:".length).split(",")) {
+		shares[part[0]] = Number(part.slice(1));
+	}
+	return shares;
+}
+
+function purchaseNotation() {
+	const parts = view.chains.filter((c) => purchase[c.initial] > 0).map((c) => c.initial + purchase[c.initial]);
+	return parts.length > 0 ? "buy:" + parts.join(",") : "pass";
+}
+
+// renderPurchase
+// counters for the shares of each chain which can be bought, which make up the purchase
+function renderPurchase(box) {
+	const buys = actions.filter((a) => a.startsWith("buy:")).map(parseBuy);
+
+	// whether there's a legal purchase with one more of the chain's shares than picked so far
+	const canBuyMore = (initial) => {
+		const wanted = {...purchase, [initial]: (purchase[initial] || 0) + 1};
+		return buys.some((shares) => Object.entries(wanted).every(([i, n]) => (shares[i] || 0) >= n));
+	};
+
+	for (const chain of view.chains) {
+		if (!buys.some((shares) => shares[chain.initial] > 0)) {
+			continue;
+		}
+
+		const n = purchase[chain.initial] || 0;
+		const change = (by) => {
+			purchase[chain.initial] = n + by;
+			renderActions();
+		};
+
+		box.append(el("span", {className: "purchase"},
+			chainBadge(chain),
+			el("button", {textContent: "−", disabled: n === 0, onclick: () => change(-1)}),
+			String(n),
+			el("button", {textContent: "+", disabled: !canBuyMore(chain.initial), onclick: () => change(1)}),
+		));
+	}
+
+	const notation = purchaseNotation();
+	const cost = view.chains.reduce((sum, c) => sum + (purchase[c.initial] || 0) * c.price, 0);
+	box.append(el("button", {
+		textContent: notation === "pass" ? "Buy nothing" : `Buy for $${cost}`,
+		disabled: !actions.includes(notation),
+		onclick: () => play(notation),
+	}));
+}
+
+// renderInventories
+// the chains and everyone's money and shares, laid out like the terminal's inventories
+function renderInventories() {
+	const table = $("inventories");
+	table.replaceChildren();
+
+	const row = (first, second, cells, last) => el("tr", {}, el("td", {textContent: first}), el("td", {textContent: second}), ...cells.map((c) => el("td", {}, c)), el("td", {textContent: last}));
+
+	const head = el("tr", {}, el("th"), el("th", {textContent: "Money"}));
+	for (const chain of view.chains) {
+		head.append(el("th", {}, chainBadge(chain)));
+	}
+	head.append(el("th", {textContent: "Net Worth"}));
+	table.append(head);
+
+	table.append(row("Size", "", view.chains.map((c) => String(c.size)), ""));
+	table.append(row("Price", "", view.chains.map((c) => (c.price > 0 ? `$${c.price}` : "–")), ""));
+
+	for (const player of view.players) {
+		const tr = row(player.name, `$${player.money}`, player.stocks.map(String), `$${player.net_worth}`);
+		tr.classList.toggle("current", player.id === view.current_player);
+		tr.classList.toggle("you", player.id === view.player_id);
+		table.append(tr);
+	}
+
+	const bank = row("In Bank", "", view.chains.map((c) => String(c.bank)), "");
+	bank.classList.add("muted");
+	table.append(bank);
+}
+
+if (gameId) {
+	$("game").hidden = false;
+	follow();
+} else {
+	$("setup").hidden = false;
+	addOpponent("mcts:200");
+	$("add-opponent").onclick = () => addOpponent("random");
+	$("setup-form").onsubmit = createGame;
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// key
+// a key pressed, either the rune typed or one of the special keys below
+type key rune
+
+const (
+	keyUp key = -1 - iota
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyQuit
+)
+
+// parseKeys
+// the keys in a chunk of raw terminal input. arrow keys arrive as escape sequences, which a single read
+// doesn't split in practice
+func parseKeys(input []byte) []key {
+	var keys []key
+
+	for len(input) > 0 {
+		if input[0] == 0x1b {
+			// ESC [ A or ESC O A, depending on the terminal's cursor key mode
+			if len(input) >= 3 && (input[1] == '[' || input[1] == 'O') {
+				switch input[2] {
+				case 'A':
+					keys = append(keys, keyUp)
+				case 'B':
+					keys = append(keys, keyDown)
+				case 'C':
+					keys = append(keys, keyRight)
+				case 'D':
+					keys = append(keys, keyLeft)
+				}
+
+				// skip the rest of longer sequences, like ESC [ 1 ; 5 C
+				n := 2
+				for n < len(input) && (input[n] < 0x40 || input[n] > 0x7e) {
+					n++
+				}
+				input = input[n+1:]
+				continue
+			}
+
+			// escape on its own does nothing
+			input = input[1:]
+			continue
+		}
+
+		r, n := utf8.DecodeRune(input)
+		input = input[n:]
+
+		switch r {
+		case '\r', '\n':
+			keys = append(keys, keyEnter)
+		case 0x03, 0x04, 'q', 'Q':
+			// ctrl-c and ctrl-d don't send signals in raw mode
+			keys = append(keys, keyQuit)
+		case 'k':
+			keys = append(keys, keyUp)
+		case 'j':
+			keys = append(keys, keyDown)
+		case 'h':
+			keys = append(keys, keyLeft)
+		case 'l', '\t':
+			keys = append(keys, keyRight)
+		default:
+			keys = append(keys, key(r))
+		}
+	}
+
+	return keys
+}
+
+// readKeys
+// sends the keys read from r until it fails
+func readKeys(r io.Reader, keys chan<- key) {
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		for _, k := range parseKeys(buf[:n]) {
+			keys <- k
+		}
+
+		if err != nil {
+			close(keys)
+			return
+		}
+	}
+}
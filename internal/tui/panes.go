@@ -0,0 +1,272 @@
+package tui
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/util"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the width of the board pane, and the gap between it and the inventories when they fit beside it
+const (
+	boardWidth = 3 + acquire.BOARD_MAX_X*3
+	paneGap    = 2
+)
+
+func bold(s string) string {
+	return "\033[1m" + s + "\033[0m"
+}
+
+func dim(s string) string {
+	return "\033[2m" + s + "\033[0m"
+}
+
+func reverse(s string) string {
+	return "\033[7m" + s + "\033[0m"
+}
+
+// frame
+// every line of the screen, width by height: the status line, the board and inventories, the log
+// and the prompt at the bottom. the log gets whatever room is left
+func (u *UI) frame(cols int, rows int) []string {
+	prompt := u.promptPane()
+
+	top := []string{reverse(pad(" "+u.statusLine(), cols))}
+
+	board := append(u.boardPane(), "", u.handLine())
+	inventories := u.inventoryPane()
+
+	inventoryWidth := 0
+	for _, line := range inventories {
+		if w := width(line); w > inventoryWidth {
+			inventoryWidth = w
+		}
+	}
+
+	if boardWidth+paneGap+inventoryWidth <= cols {
+		for i := 0; i < len(board) || i < len(inventories); i++ {
+			left, right := "", ""
+			if i < len(board) {
+				left = board[i]
+			}
+			if i < len(inventories) {
+				right = inventories[i]
+			}
+			top = append(top, pad(left, boardWidth+paneGap)+right)
+		}
+	} else {
+		top = append(top, board...)
+		top = append(top, "")
+		top = append(top, inventories...)
+	}
+
+	// the newest lines of the log which fit between the panes and the prompt
+	room := rows - len(top) - len(prompt) - 1
+	if room > 0 {
+		log := u.log
+		if len(log) > room {
+			log = log[len(log)-room:]
+		}
+
+		top = append(top, "")
+		top = append(top, log...)
+	}
+
+	// the prompt stays at the bottom, whatever doesn't fit above it is cut off
+	above := util.Max(0, rows-len(prompt))
+	if len(top) > above {
+		top = top[:above]
+	}
+	for len(top) < above {
+		top = append(top, "")
+	}
+
+	return append(top, prompt...)
+}
+
+func (u *UI) statusLine() string {
+	game := u.game
+	status := fmt.Sprintf("Acquire | Turn %d | Tiles Left: %d", game.Turn, game.NumRemainingTiles())
+
+	switch {
+	case game.IsTerminal():
+		return status + " | Game Over"
+	case u.picker != nil:
+		return status + " | " + game.ActivePlayer().Name() + ": " + game.NextActionType.String()
+	default:
+		return status + " | Waiting for " + game.ActivePlayer().Description()
+	}
+}
+
+// boardPane
+// the board as the viewer sees it, with the tile under the cursor picked out when placing a tile
+func (u *UI) boardPane() []string {
+	view := acquire.NewView(u.game, u.viewer)
+
+	tiles, _ := u.picker.(*tilePicker)
+
+	header := "   "
+	for x := 1; x <= acquire.BOARD_MAX_X; x++ {
+		header += pad(strconv.Itoa(x), 3)
+	}
+	lines := []string{dim(header)}
+
+	for y, row := range view.Board {
+		line := dim(string(rune('a'+y))) + "  "
+
+		for x, cell := range row {
+			tile := strconv.Itoa(x+1) + string(rune('A'+y))
+
+			mark := " "
+			if tile == view.LastPlaced {
+				mark = "*"
+			}
+
+			switch cell {
+			case '.':
+				legal := false
+				if tiles != nil {
+					t, _ := acquire.TileFromString(tile)
+					_, legal = tiles.legal[t]
+				}
+
+				switch {
+				case legal && tiles.selected().String() == tile:
+					line += "\033[1;38;5;16;48;5;226m◆ \033[0m "
+				case legal:
+					line += "\033[1;38;5;231;48;5;240m○ \033[0m "
+				default:
+					line += "\033[38;5;240m□\033[0m  "
+				}
+
+			case '=':
+				line += "\033[1;38;5;231;48;5;245m■" + mark + "\033[0m "
+
+			default:
+				hotel, _ := acquire.ChainFromInitial(string(cell))
+				line += u.palette.Paint(hotel, string(cell)+mark) + " "
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// handLine
+// the viewer's tiles, with the one under the cursor picked out and those which can't be placed dimmed
+func (u *UI) handLine() string {
+	if u.viewer == 0 {
+		return ""
+	}
+
+	player := u.game.GetPlayerById(u.viewer)
+	line := player.Name() + "'s tiles:"
+
+	tiles, _ := u.picker.(*tilePicker)
+	for _, tile := range player.Tiles {
+		if tile == acquire.NoTile {
+			continue
+		}
+
+		name := pad(tile.String(), 3)
+		switch {
+		case tiles == nil:
+			line += " " + name
+		case tiles.selected() == tile:
+			line += " " + reverse(bold(name))
+		case tiles.legal[tile] != nil:
+			line += " " + bold(name)
+		default:
+			line += " " + dim(name)
+		}
+	}
+
+	return line
+}
+
+// inventoryPane
+// the chains, with the shares each seat holds in them, and everyone's money
+func (u *UI) inventoryPane() []string {
+	view := acquire.NewView(u.game, u.viewer)
+
+	header := "    Size Price Bank"
+	for _, p := range view.Players {
+		header += rfill(strconv.Itoa(p.Id), 3)
+	}
+	lines := []string{dim(header)}
+
+	for i, chain := range view.Chains {
+		safe := " "
+		if chain.Safe {
+			safe = "•"
+		}
+
+		price := "-"
+		if chain.Price > 0 {
+			price = fmt.Sprintf("$%d", chain.Price)
+		}
+
+		line := u.palette.Paint(acquire.HotelChainList[i], " "+chain.Initial+safe) + " "
+		line += rfill(strconv.Itoa(chain.Size), 4) + rfill(price, 6) + rfill(strconv.Itoa(chain.Bank), 5)
+		for _, p := range view.Players {
+			line += rfill(strconv.Itoa(p.Stocks[i]), 3)
+		}
+		lines = append(lines, line)
+	}
+
+	nameWidth := 0
+	for _, p := range view.Players {
+		nameWidth = util.Max(nameWidth, len([]rune(p.Name)))
+	}
+	nameWidth = util.Max(util.Min(nameWidth, 12), len("Player"))
+
+	lines = append(lines, "", dim(fmt.Sprintf("    %s  Money  Worth", pad("Player", nameWidth))))
+	for _, p := range view.Players {
+		line := fmt.Sprintf("%d %s %6s %6s", p.Id, pad(truncate(p.Name, nameWidth), nameWidth), fmt.Sprintf("$%d", p.Money), fmt.Sprintf("$%d", p.NetWorth))
+		if p.Id == view.ActivePlayer {
+			lines = append(lines, bold("▸ "+line))
+			continue
+		}
+		lines = append(lines, "  "+line)
+	}
+
+	return lines
+}
+
+// rfill
+// s with spaces in front, out to n columns
+func rfill(s string, n int) string {
+	if w := width(s); w < n {
+		return strings.Repeat(" ", n-w) + s
+	}
+	return s
+}
+
+// promptPane
+// the decision being made, or what's being waited on
+func (u *UI) promptPane() []string {
+	if u.picker != nil {
+		return append([]string{""}, u.picker.prompt()...)
+	}
+
+	game := u.game
+	if game.IsTerminal() {
+		winners := make([]string, 0)
+		for _, id := range game.Winners() {
+			player := game.GetPlayerById(int(id))
+			winners = append(winners, fmt.Sprintf("%s ($%d)", player.Name(), player.NetWorth(game)))
+		}
+
+		return []string{
+			"",
+			bold("Game over: ") + game.EndReason,
+			"Won by " + strings.Join(winners, ", "),
+			dim("press any key to leave"),
+		}
+	}
+
+	return []string{"", dim("q to quit")}
+}
@@ -0,0 +1,422 @@
+package tui
+
+import (
+	"acquire/internal/acquire"
+	"fmt"
+	"git.sr.ht/~bonbon/gmcts"
+	"strings"
+)
+
+// picker
+// picks one of the actions with the keyboard, for one kind of decision
+type picker interface {
+	// press
+	// moves the cursor for the key, returning the action once one is picked (nil until then)
+	press(k key) gmcts.Action
+
+	// prompt
+	// the lines describing the decision, with the cursor on them
+	prompt() []string
+}
+
+// newPicker
+// the picker for the game's next decision among the actions
+func newPicker(game *acquire.Game, actions []gmcts.Action, palette acquire.Palette) picker {
+	switch game.NextActionType {
+	case acquire.ActionType_PlaceTile:
+		return newTilePicker(game, actions)
+	case acquire.ActionType_PickHotelToFound:
+		return newChainPicker("Found a chain", actions, palette)
+	case acquire.ActionType_PickHotelToMerge:
+		return newChainPicker("Pick the chain which acquires the others", actions, palette)
+	case acquire.ActionType_Merge:
+		return newMergePicker(game, actions, palette)
+	case acquire.ActionType_PurchaseStock:
+		return newPurchasePicker(game, actions, palette)
+	default:
+		panic(fmt.Sprintf("action %s is not handled", game.NextActionType))
+	}
+}
+
+// move
+// the cursor moved by the arrow key (left and up go back) within n places
+func move(cursor int, k key, n int) int {
+	switch k {
+	case keyLeft, keyUp:
+		return (cursor + n - 1) % n
+	case keyRight, keyDown:
+		return (cursor + 1) % n
+	}
+	return cursor
+}
+
+// tilePicker
+// picks a tile to place from the player's hand, which is drawn along with the board
+type tilePicker struct {
+	legal map[acquire.Tile]gmcts.Action
+	// the tiles in the hand which can be placed, in hand order
+	order  []acquire.Tile
+	cursor int
+}
+
+func newTilePicker(game *acquire.Game, actions []gmcts.Action) *tilePicker {
+	p := &tilePicker{legal: make(map[acquire.Tile]gmcts.Action)}
+
+	for _, action := range actions {
+		p.legal[action.(acquire.Action_PlaceTile).Tile] = action
+	}
+
+	for _, tile := range game.ActivePlayer().Tiles {
+		if _, ok := p.legal[tile]; ok && tile != acquire.NoTile {
+			p.order = append(p.order, tile)
+		}
+	}
+
+	return p
+}
+
+// selected
+// the tile under the cursor
+func (p *tilePicker) selected() acquire.Tile {
+	return p.order[p.cursor]
+}
+
+func (p *tilePicker) press(k key) gmcts.Action {
+	if k == keyEnter {
+		return p.legal[p.selected()]
+	}
+
+	// typing a tile's column jumps to it, e.g. 5 for 5C
+	for i, tile := range p.order {
+		column := strings.TrimRight(tile.String(), "ABCDEFGHI")
+		if string(k) == column || (k == '0' && column == "10") {
+			p.cursor = i
+			return nil
+		}
+	}
+
+	p.cursor = move(p.cursor, k, len(p.order))
+	return nil
+}
+
+func (p *tilePicker) prompt() []string {
+	return []string{
+		fmt.Sprintf("Place a tile: %s", bold(p.selected().String())),
+		dim("←/→ or the tile's column to pick, enter to place"),
+	}
+}
+
+// chainPicker
+// picks a chain, either to found or to acquire the others in a tied merger
+type chainPicker struct {
+	title   string
+	palette acquire.Palette
+	hotels  []acquire.Hotel
+	actions []gmcts.Action
+	cursor  int
+}
+
+func newChainPicker(title string, actions []gmcts.Action, palette acquire.Palette) *chainPicker {
+	p := &chainPicker{title: title, palette: palette, actions: actions}
+
+	for _, action := range actions {
+		switch a := action.(type) {
+		case acquire.Action_PickHotelToFound:
+			p.hotels = append(p.hotels, a.Hotel)
+		case acquire.Action_PickHotelToMerge:
+			p.hotels = append(p.hotels, a.Hotel)
+		}
+	}
+
+	return p
+}
+
+func (p *chainPicker) press(k key) gmcts.Action {
+	if k == keyEnter {
+		return p.actions[p.cursor]
+	}
+
+	// typing a chain's initial jumps to it
+	for i, hotel := range p.hotels {
+		if strings.EqualFold(string(k), hotel.Initial()) {
+			p.cursor = i
+			return nil
+		}
+	}
+
+	p.cursor = move(p.cursor, k, len(p.hotels))
+	return nil
+}
+
+func (p *chainPicker) prompt() []string {
+	// every chain's name doesn't fit on one line, so only the one under the cursor is spelled out
+	chains := make([]string, len(p.hotels))
+	for i, hotel := range p.hotels {
+		chain := p.palette.Paint(hotel, " "+hotel.Initial()+" ")
+		if i == p.cursor {
+			chains[i] = "[" + chain + "]"
+			continue
+		}
+		chains[i] = " " + chain + " "
+	}
+
+	return []string{
+		p.title + ": " + bold(p.hotels[p.cursor].String()),
+		strings.Join(chains, " "),
+		dim("←/→ or the chain's initial to pick, enter to choose"),
+	}
+}
+
+// change
+// how much the key changes an amount by: up and + add one, down and - take one away
+func change(k key) int {
+	switch k {
+	case keyUp, '+', '=':
+		return 1
+	case keyDown, '-', '_':
+		return -1
+	}
+	return 0
+}
+
+// mergePicker
+// picks how many shares of the chain being merged to sell and trade, holding the rest
+type mergePicker struct {
+	palette   acquire.Palette
+	merged    acquire.Hotel
+	acquirer  acquire.Hotel
+	shares    int
+	price     int
+	available int
+
+	// the actions by the number of shares traded and sold
+	legal map[[2]int]gmcts.Action
+	trade int
+	sell  int
+	// whether the cursor is on trading rather than selling
+	trading bool
+}
+
+func newMergePicker(game *acquire.Game, actions []gmcts.Action, palette acquire.Palette) *mergePicker {
+	p := &mergePicker{
+		palette:   palette,
+		acquirer:  game.MergerState.AcquiringHotel,
+		legal:     make(map[[2]int]gmcts.Action),
+		available: game.Stocks[game.MergerState.AcquiringHotel.Index()],
+	}
+
+	for idx, remaining := range game.MergerState.ChainsToMerge {
+		if remaining > 0 {
+			p.merged = acquire.ChainFromIdx(idx)
+			break
+		}
+	}
+
+	p.shares = game.ActivePlayer().Stocks[p.merged.Index()]
+	p.price = p.merged.Value(game, 1)
+
+	for _, action := range actions {
+		trade, sell := 0, 0
+		for _, sub := range action.(acquire.Action_Merge).Actions {
+			switch sub.MergeType {
+			case acquire.Trade:
+				trade += sub.Amount
+			case acquire.Sell:
+				sell += sub.Amount
+			}
+		}
+		p.legal[[2]int{trade, sell}] = action
+	}
+
+	return p
+}
+
+func (p *mergePicker) press(k key) gmcts.Action {
+	switch k {
+	case keyEnter:
+		return p.legal[[2]int{p.trade, p.sell}]
+	case keyLeft, keyRight:
+		p.trading = !p.trading
+		return nil
+	}
+
+	by := change(k)
+	if by == 0 {
+		return nil
+	}
+
+	if !p.trading {
+		if _, ok := p.legal[[2]int{p.trade, p.sell + by}]; ok {
+			p.sell += by
+		}
+		return nil
+	}
+
+	// trading more leaves less to sell, so sell as many as are still left
+	trade := p.trade + 2*by
+	for sell := p.sell; sell >= 0; sell-- {
+		if _, ok := p.legal[[2]int{trade, sell}]; ok {
+			p.trade, p.sell = trade, sell
+			break
+		}
+	}
+
+	return nil
+}
+
+func (p *mergePicker) prompt() []string {
+	merged := p.palette.Paint(p.merged, " "+p.merged.String()+" ")
+	acquirer := p.palette.Paint(p.acquirer, " "+p.acquirer.String()+" ")
+
+	sell := fmt.Sprintf("Sell ‹%d› for $%d", p.sell, p.sell*p.price)
+	trade := fmt.Sprintf("Trade ‹%d› for %d of %d %s", p.trade, p.trade/2, p.available, p.acquirer.String())
+	if p.trading {
+		trade = reverse(trade)
+	} else {
+		sell = reverse(sell)
+	}
+
+	return []string{
+		fmt.Sprintf("%s merges into %s, you have %d shares worth $%d each:", merged, acquirer, p.shares, p.price),
+		fmt.Sprintf("%s   %s   Hold %d", sell, trade, p.shares-p.trade-p.sell),
+		dim("←/→ to pick selling or trading, ↑/↓ to change how many, enter to confirm"),
+	}
+}
+
+// purchasePicker
+// picks the shares to buy at the end of the turn
+type purchasePicker struct {
+	palette acquire.Palette
+	money   int
+	hotels  []acquire.Hotel
+	prices  map[acquire.Hotel]int
+	actions []gmcts.Action
+
+	// the number of shares picked in each chain, and the chain under the cursor
+	counts map[acquire.Hotel]int
+	cursor int
+}
+
+func newPurchasePicker(game *acquire.Game, actions []gmcts.Action, palette acquire.Palette) *purchasePicker {
+	p := &purchasePicker{
+		palette: palette,
+		money:   game.ActivePlayer().Money,
+		prices:  make(map[acquire.Hotel]int),
+		actions: actions,
+		counts:  make(map[acquire.Hotel]int),
+	}
+
+	buyable := make(map[acquire.Hotel]bool)
+	for _, action := range actions {
+		for hotel, n := range action.(acquire.Action_PurchaseStock).AsMap() {
+			buyable[hotel] = buyable[hotel] || n > 0
+		}
+	}
+
+	for _, hotel := range acquire.HotelChainList {
+		if buyable[hotel] {
+			p.hotels = append(p.hotels, hotel)
+			p.prices[hotel] = hotel.Value(game, 1)
+		}
+	}
+
+	return p
+}
+
+// find
+// the action buying the given shares, nil if they can't be bought
+func (p *purchasePicker) find(counts map[acquire.Hotel]int) gmcts.Action {
+	for _, action := range p.actions {
+		buys := action.(acquire.Action_PurchaseStock).AsMap()
+
+		same := true
+		for _, hotel := range acquire.HotelChainList {
+			same = same && buys[hotel] == counts[hotel]
+		}
+		if same {
+			return action
+		}
+	}
+
+	return nil
+}
+
+func (p *purchasePicker) cost() int {
+	cost := 0
+	for hotel, n := range p.counts {
+		cost += n * p.prices[hotel]
+	}
+	return cost
+}
+
+func (p *purchasePicker) press(k key) gmcts.Action {
+	if k == keyEnter {
+		return p.find(p.counts)
+	}
+
+	if len(p.hotels) == 0 {
+		return nil
+	}
+
+	// typing a chain's initial buys another of its shares
+	for i, hotel := range p.hotels {
+		if strings.EqualFold(string(k), hotel.Initial()) {
+			p.cursor = i
+			k = '+'
+		}
+	}
+
+	if k == keyLeft || k == keyRight {
+		p.cursor = move(p.cursor, k, len(p.hotels))
+		return nil
+	}
+
+	by := change(k)
+	if by == 0 {
+		return nil
+	}
+
+	hotel := p.hotels[p.cursor]
+	counts := make(map[acquire.Hotel]int)
+	for h, n := range p.counts {
+		counts[h] = n
+	}
+	counts[hotel] += by
+
+	if counts[hotel] >= 0 && p.find(counts) != nil {
+		p.counts = counts
+	}
+
+	return nil
+}
+
+func (p *purchasePicker) prompt() []string {
+	chains := make([]string, len(p.hotels))
+	for i, hotel := range p.hotels {
+		chain := fmt.Sprintf("%s %d", p.palette.Paint(hotel, " "+hotel.Initial()+" "), p.counts[hotel])
+		if i == p.cursor {
+			chain = "[" + chain + "]"
+		} else {
+			chain = " " + chain + " "
+		}
+		chains[i] = chain
+	}
+
+	total := "buy nothing"
+	if p.cost() > 0 {
+		total = fmt.Sprintf("buy for $%d, leaving $%d", p.cost(), p.money-p.cost())
+	}
+
+	line := "Nothing left to buy"
+	if len(p.hotels) > 0 {
+		hotel := p.hotels[p.cursor]
+		line = fmt.Sprintf("%s is $%d a share, %s", hotel.String(), p.prices[hotel], total)
+	}
+
+	return []string{
+		fmt.Sprintf("Buy up to 3 shares, you have $%d:", p.money),
+		strings.Join(chains, " "),
+		line,
+		dim("←/→ or an initial to pick a chain, ↑/↓ to change how many, enter to buy"),
+	}
+}
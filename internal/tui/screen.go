@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// screen
+// draws whole frames onto the terminal, only rewriting the lines which changed since the last frame
+type screen struct {
+	w io.Writer
+	// what's on the terminal, and the size it was drawn for
+	lines         []string
+	width, height int
+}
+
+// draw
+// puts the frame's lines on the terminal, which is width by height. a change of size redraws everything
+func (s *screen) draw(lines []string, width int, height int) error {
+	out := strings.Builder{}
+
+	if width != s.width || height != s.height {
+		out.WriteString("\033[2J")
+		s.lines = nil
+		s.width, s.height = width, height
+	}
+
+	for y := 0; y < height; y++ {
+		line := ""
+		if y < len(lines) {
+			line = truncate(lines[y], width)
+		}
+
+		if y < len(s.lines) && s.lines[y] == line {
+			continue
+		}
+
+		// move to the start of the line, draw it and clear whatever was after it
+		fmt.Fprintf(&out, "\033[%d;1H%s\033[K", y+1, line)
+	}
+
+	s.lines = make([]string, height)
+	for y := range s.lines {
+		if y < len(lines) {
+			s.lines[y] = truncate(lines[y], width)
+		}
+	}
+
+	if out.Len() == 0 {
+		return nil
+	}
+
+	_, err := io.WriteString(s.w, out.String())
+	return err
+}
+
+// escapeLen
+// the length of the ansi escape sequence at the start of s, 0 if there isn't one
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != 0x1b || s[1] != '[' {
+		return 0
+	}
+
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+
+	return len(s)
+}
+
+// width
+// how many columns s takes up on the terminal, leaving out its escape sequences
+func width(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			i += l
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		n++
+	}
+
+	return n
+}
+
+// truncate
+// cuts s down to n columns, keeping its escape sequences whole
+func truncate(s string, n int) string {
+	cols := 0
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			i += l
+			continue
+		}
+
+		if cols == n {
+			// whatever style was cut off mustn't run on
+			return s[:i] + "\033[0m"
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		cols++
+	}
+
+	return s
+}
+
+// pad
+// s filled out with spaces to n columns
+func pad(s string, n int) string {
+	if w := width(s); w < n {
+		return s + strings.Repeat(" ", n-w)
+	}
+	return s
+}
@@ -0,0 +1,244 @@
+// Package tui plays games in the terminal, drawn as panes which are redrawn in place as the game goes on:
+// the board, the chains with everyone's shares and money, a log of what's happened, and a prompt where human
+// seats pick their moves with the keyboard.
+//
+// a UI is shared by every human seat at the terminal (see Seat), and is told about the game by the Play hooks
+// (see Show and Event). q or ctrl-c quits
+package tui
+
+import (
+	"acquire/internal/acquire"
+	"acquire/internal/ai"
+	"context"
+	"errors"
+	"git.sr.ht/~bonbon/gmcts"
+	"golang.org/x/term"
+	"io"
+	"os"
+)
+
+// ErrQuit
+// returned by the seats' SelectAction when the player quits
+var ErrQuit = errors.New("quit before the game was over")
+
+// the size assumed when the terminal's size can't be found
+const (
+	defaultCols = 80
+	defaultRows = 24
+)
+
+// UI
+// the game drawn on a terminal. it isn't safe to use from more than one goroutine, which Play doesn't do
+type UI struct {
+	screen  screen
+	size    func() (int, int)
+	palette acquire.Palette
+	keys    chan key
+	restore func() error
+	// the first error drawing
+	err error
+
+	game *acquire.Game
+	// the human seat whose tiles are shown, the last one to pick a move
+	viewer int
+	log    []string
+	// the decision being made at the terminal, nil while waiting on someone else
+	picker picker
+}
+
+// Open
+// takes over the terminal until Close. quit is called if the player quits while it's someone else's turn,
+// e.g. to cancel the game's context
+func Open(palette acquire.Palette, quit func()) (*UI, error) {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		return nil, errors.New("the tui needs a terminal to play in")
+	}
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return nil, err
+	}
+
+	size := func() (int, int) {
+		cols, rows, err := term.GetSize(out)
+		if err != nil {
+			return defaultCols, defaultRows
+		}
+		return cols, rows
+	}
+
+	// the alternate screen keeps the terminal's scrollback as it was, and the cursor is hidden while drawing
+	_, err = io.WriteString(os.Stdout, "\033[?1049h\033[?25l")
+	if err != nil {
+		term.Restore(in, state)
+		return nil, err
+	}
+
+	u := New(os.Stdin, os.Stdout, size, palette, quit)
+	u.restore = func() error {
+		io.WriteString(os.Stdout, "\033[0m\033[?25h\033[?1049l")
+		return term.Restore(in, state)
+	}
+
+	return u, nil
+}
+
+// New
+// a UI reading keys from in and drawing on out, a terminal of the given size. quit is called when q or ctrl-c
+// is pressed, and can be nil
+func New(in io.Reader, out io.Writer, size func() (int, int), palette acquire.Palette, quit func()) *UI {
+	u := &UI{
+		screen:  screen{w: out},
+		size:    size,
+		palette: palette,
+		keys:    make(chan key, 16),
+	}
+
+	raw := make(chan key)
+	go readKeys(in, raw)
+
+	go func() {
+		for k := range raw {
+			if k == keyQuit && quit != nil {
+				quit()
+			}
+
+			// keys pressed while no one is picking a move pile up, so the oldest are dropped
+			select {
+			case u.keys <- k:
+			default:
+			}
+		}
+		close(u.keys)
+	}()
+
+	return u
+}
+
+// Close
+// gives the terminal back the way it was
+func (u *UI) Close() error {
+	if u.restore != nil {
+		err := u.restore()
+		if u.err == nil {
+			u.err = err
+		}
+	}
+
+	return u.err
+}
+
+// Show
+// draws the game, which is waiting on its active player
+func (u *UI) Show(game *acquire.Game) {
+	u.game = game
+	u.draw()
+}
+
+// Event
+// adds the event to the log and draws the game after it
+func (u *UI) Event(event ai.Event) {
+	u.Log(event.Description)
+	u.Show(event.Game)
+}
+
+// Log
+// adds a line to the log
+func (u *UI) Log(line string) {
+	u.log = append(u.log, line)
+	if u.game != nil {
+		u.draw()
+	}
+}
+
+// Finish
+// draws the game once it's over, waiting for a key before going on
+func (u *UI) Finish(game *acquire.Game) {
+	u.picker = nil
+	u.Show(game)
+	<-u.keys
+}
+
+func (u *UI) draw() {
+	cols, rows := u.size()
+	err := u.screen.draw(u.frame(cols, rows), cols, rows)
+	if err != nil && u.err == nil {
+		u.err = err
+	}
+}
+
+// pick
+// has the player pick one of the actions at the terminal
+func (u *UI) pick(ctx context.Context, playerId int, game *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	// anything typed while waiting was meant for something else, unless it was to quit
+	for len(u.keys) > 0 {
+		if k := <-u.keys; k == keyQuit {
+			return nil, ErrQuit
+		}
+	}
+
+	u.viewer = playerId
+	u.game = game
+	u.picker = newPicker(game, actions, u.palette)
+	defer func() {
+		u.picker = nil
+	}()
+
+	for {
+		u.draw()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case k, ok := <-u.keys:
+			if !ok || k == keyQuit {
+				return nil, ErrQuit
+			}
+
+			action := u.picker.press(k)
+			if action != nil {
+				return action, nil
+			}
+		}
+	}
+}
+
+// Seat
+// an agent for a human seat, who picks their moves at the terminal
+func (u *UI) Seat(name string) ai.Agent {
+	return &seat{ui: u, name: name}
+}
+
+type seat struct {
+	ui   *UI
+	name string
+	id   int
+}
+
+func (s *seat) Name() string {
+	return s.name
+}
+
+func (s *seat) OnGameStart(id int, rules acquire.Rules) {
+	s.id = id
+
+	// the first seat's tiles are shown until someone picks a move
+	if s.ui.viewer == 0 {
+		s.ui.viewer = id
+	}
+}
+
+func (s *seat) Observe(ai.Event) {}
+
+func (s *seat) SelectAction(ctx context.Context, view *acquire.Game, actions []gmcts.Action) (gmcts.Action, error) {
+	// there's nothing to decide
+	if len(actions) == 1 {
+		return actions[0], nil
+	}
+
+	return s.ui.pick(ctx, s.id, view, actions)
+}
+
+func (s *seat) OnGameEnd(ai.GameResult) {}
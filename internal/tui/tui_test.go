@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"acquire/internal/acquire"
+	"context"
+	"errors"
+	"git.sr.ht/~bonbon/gmcts"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("\x1b[A\x1bOB\x1b[C\x1b[Dx\r\x03\x1b[1;5C\x1bjk+"))
+	expected := []key{keyUp, keyDown, keyRight, keyLeft, 'x', keyEnter, keyQuit, keyDown, keyUp, '+'}
+
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+	for i := range keys {
+		if keys[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, keys)
+		}
+	}
+}
+
+func TestScreen(t *testing.T) {
+	out := strings.Builder{}
+	s := screen{w: &out}
+
+	s.draw([]string{"one", "two"}, 10, 3)
+	if !strings.HasPrefix(out.String(), "\033[2J") || !strings.Contains(out.String(), "\033[2;1Htwo\033[K") {
+		t.Fatalf("the first frame should be drawn in full, got %q", out.String())
+	}
+
+	out.Reset()
+	s.draw([]string{"one", "2"}, 10, 3)
+	if out.String() != "\033[2;1H2\033[K" {
+		t.Fatalf("only the changed line should be drawn, got %q", out.String())
+	}
+
+	out.Reset()
+	s.draw([]string{"one", "2"}, 10, 3)
+	if out.String() != "" {
+		t.Fatalf("an unchanged frame shouldn't draw anything, got %q", out.String())
+	}
+
+	out.Reset()
+	s.draw([]string{"one", "2"}, 2, 3)
+	if !strings.HasPrefix(out.String(), "\033[2J") || !strings.Contains(out.String(), "\033[1;1Hon\033[0m\033[K") {
+		t.Fatalf("a new size should redraw everything, cut down to fit, got %q", out.String())
+	}
+}
+
+func TestWidth(t *testing.T) {
+	s := "\033[1ma□\033[0mbc"
+	if width(s) != 4 {
+		t.Errorf("escape codes shouldn't take up any room, got %d", width(s))
+	}
+
+	if truncate(s, 2) != "\033[1ma□\033[0m\033[0m" || truncate(s, 10) != s {
+		t.Errorf("unexpected truncation %q", truncate(s, 2))
+	}
+
+	if pad(s, 6) != s+"  " || rfill(s, 6) != "  "+s {
+		t.Error("padding should go by what's shown")
+	}
+}
+
+// position
+// the game at the position, failing the test if it doesn't parse
+func position(t *testing.T, text string) (*acquire.Game, []gmcts.Action) {
+	game, err := acquire.ParsePosition(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return game, game.GetActions()
+}
+
+// pickWith
+// the notation of the action the keys pick
+func pickWith(t *testing.T, p picker, keys string) string {
+	for _, k := range parseKeys([]byte(keys)) {
+		if action := p.press(k); action != nil {
+			return acquire.Notation(action)
+		}
+	}
+
+	t.Fatalf("nothing was picked with %q", keys)
+	return ""
+}
+
+func TestPickers(t *testing.T) {
+	game, actions := position(t, `player 1: tiles 1A 5C 3I 12I`)
+	tiles := newPicker(game, actions, acquire.PaletteClassic)
+	if notation := pickWith(t, tiles, "\r"); notation != "1A" {
+		t.Errorf("the first tile should be picked to begin with, got %s", notation)
+	}
+	if notation := pickWith(t, tiles, "\x1b[C\x1b[C\r"); notation != "3I" {
+		t.Errorf("moving right twice should pick 3I, got %s", notation)
+	}
+	if notation := pickWith(t, tiles, "\x1b[D5\r"); notation != "5C" {
+		t.Errorf("typing 5 should pick 5C, got %s", notation)
+	}
+
+	game, actions = position(t, `phase: pick hotel to found / last: 2A / a ■ ■ □ □ □ □ □ □ □ □ □ □`)
+	chains := newPicker(game, actions, acquire.PaletteClassic)
+	if notation := pickWith(t, chains, "\x1b[Ds\x1b[C\r"); notation != "found:F" {
+		t.Errorf("typing S then moving right should found Festival, got %s", notation)
+	}
+
+	game, actions = position(t, `phase: purchase stock / player 1: $700 / a W W □ S S □ □ □ □ □ □ □`)
+	purchase := newPicker(game, actions, acquire.PaletteClassic)
+	if notation := pickWith(t, purchase, "\x1b[B\r"); notation != "pass" {
+		t.Errorf("buying nothing should pass, got %s", notation)
+	}
+	if notation := pickWith(t, purchase, "\x1b[A\x1b[A\x1b[C+\r"); notation != "buy:W2,S1" {
+		t.Errorf("$700 should buy two Worldwide and a Sackson, got %s", notation)
+	}
+
+	game, actions = position(t, `phase: merge / last: 3A / player 1: stocks W5 / a W W ■ S S S □ □ □ □ □ □`)
+	merge := newPicker(game, actions, acquire.PaletteClassic)
+	if notation := pickWith(t, merge, "\x1b[B\r"); notation != "hold" {
+		t.Errorf("shares can't be sold below zero, got %s", notation)
+	}
+	if notation := pickWith(t, merge, "\x1b[A\x1b[A\x1b[A\x1b[C\x1b[A\x1b[A\x1b[A\r"); notation != "trade:4,sell:1" {
+		t.Errorf("trading 4 should leave 1 to sell, got %s", notation)
+	}
+
+	for _, p := range []picker{tiles, chains, purchase, merge} {
+		for _, line := range p.prompt() {
+			if width(line) > defaultCols {
+				t.Errorf("%T's prompt should fit the terminal, %q doesn't", p, line)
+			}
+		}
+	}
+}
+
+// drawnWriter
+// the terminal's output, which says when it's been drawn on
+type drawnWriter struct {
+	out   strings.Builder
+	drawn chan struct{}
+}
+
+func (w *drawnWriter) Write(p []byte) (int, error) {
+	select {
+	case w.drawn <- struct{}{}:
+	default:
+	}
+	return w.out.Write(p)
+}
+
+func TestUI(t *testing.T) {
+	in, keys := io.Pipe()
+	out := drawnWriter{drawn: make(chan struct{}, 1)}
+	quit := make(chan bool, 1)
+
+	size := func() (int, int) { return defaultCols, defaultRows }
+	u := New(in, &out, size, acquire.PaletteClassic, func() { quit <- true })
+
+	game, actions := position(t, `player 1: tiles 1A 5C`)
+	game.Players[0].DisplayName = "Alice"
+	seat := u.Seat("Human")
+	seat.OnGameStart(1, game.Rules)
+
+	picked := make(chan gmcts.Action)
+	go func() {
+		action, err := seat.SelectAction(context.Background(), game, actions)
+		if err != nil {
+			t.Error(err)
+		}
+		picked <- action
+	}()
+
+	// keys typed before the move is asked for are thrown away, so wait for it to be drawn
+	select {
+	case <-out.drawn:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the move was never asked for")
+	}
+
+	keys.Write([]byte("\x1b[C"))
+	keys.Write([]byte("\r"))
+
+	select {
+	case action := <-picked:
+		if acquire.Notation(action) != "5C" {
+			t.Errorf("5C should have been picked, got %s", acquire.Notation(action))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing was picked")
+	}
+
+	for _, line := range u.frame(defaultCols, defaultRows) {
+		if width(line) > defaultCols {
+			t.Errorf("the frame should fit the terminal, %q doesn't", line)
+		}
+	}
+	if len(u.frame(defaultCols, defaultRows)) != defaultRows {
+		t.Error("the frame should fill the terminal")
+	}
+	if !strings.Contains(out.out.String(), "Alice's tiles") {
+		t.Error("Alice's tiles should have been drawn")
+	}
+
+	// quitting stops the game whoever's turn it is, and ends a decision at the terminal
+	go func() {
+		_, err := seat.SelectAction(context.Background(), game, actions)
+		if !errors.Is(err, ErrQuit) {
+			t.Errorf("quitting should stop picking, got %v", err)
+		}
+		picked <- nil
+	}()
+
+	keys.Write([]byte("q"))
+
+	select {
+	case <-picked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("quitting didn't stop the seat")
+	}
+	if !<-quit {
+		t.Error("quit should have been called")
+	}
+}
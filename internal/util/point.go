@@ -14,8 +14,10 @@ type Point[T numeric] struct {
 	Y T
 }
 
-func (p Point[T]) OrthogonalNeighbours() []Point[T] {
-	return []Point[T]{
+// OrthogonalNeighbours
+// returned as an array rather than a slice so that it doesn't allocate
+func (p Point[T]) OrthogonalNeighbours() [4]Point[T] {
+	return [4]Point[T]{
 		p.North(),
 		p.South(),
 		p.East(),
@@ -85,5 +87,5 @@ func (p Point[T]) Magnitude() float64 {
 }
 
 func (p Point[T]) String() string {
-	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+	return fmt.Sprintf("(%v, %v)", p.X, p.Y)
 }
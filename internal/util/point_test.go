@@ -0,0 +1,13 @@
+package util
+
+import "testing"
+
+func TestPointString(t *testing.T) {
+	if s := (Point[int]{X: 3, Y: -2}).String(); s != "(3, -2)" {
+		t.Errorf("expected (3, -2), got %s", s)
+	}
+
+	if s := (Point[float64]{X: 1.5, Y: 2}).String(); s != "(1.5, 2)" {
+		t.Errorf("float points should print their values, got %s", s)
+	}
+}